@@ -0,0 +1,53 @@
+package rtxt
+
+import (
+	"unicode/utf16"
+	"unicode/utf8"
+)
+
+func bom(enc ReliableTxtEncoding) []byte {
+	switch enc {
+	case Utf16:
+		return bomUtf16
+	case Utf16Reverse:
+		return bomUtf16Reverse
+	case Utf32:
+		return bomUtf32
+	default:
+		return bomUtf8
+	}
+}
+
+// appendEncoded appends s to dst in the given encoding; invalid UTF-8 in s is
+// written as U+FFFD
+func appendEncoded(dst []byte, s string, enc ReliableTxtEncoding) []byte {
+	if enc != Utf16 && enc != Utf16Reverse && enc != Utf32 && utf8.ValidString(s) {
+		return append(dst, s...)
+	}
+	for _, r := range s {
+		dst = appendRune(dst, r, enc)
+	}
+	return dst
+}
+
+func appendRune(dst []byte, r rune, enc ReliableTxtEncoding) []byte {
+	switch enc {
+	case Utf16, Utf16Reverse:
+		if r1, r2 := utf16.EncodeRune(r); r1 != utf8.RuneError {
+			dst = appendUnit16(dst, uint16(r1), enc)
+			return appendUnit16(dst, uint16(r2), enc)
+		}
+		return appendUnit16(dst, uint16(r), enc)
+	case Utf32:
+		return append(dst, byte(r>>24), byte(r>>16), byte(r>>8), byte(r))
+	default:
+		return utf8.AppendRune(dst, r)
+	}
+}
+
+func appendUnit16(dst []byte, u uint16, enc ReliableTxtEncoding) []byte {
+	if enc == Utf16Reverse {
+		return append(dst, byte(u), byte(u>>8))
+	}
+	return append(dst, byte(u>>8), byte(u))
+}
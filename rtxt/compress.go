@@ -0,0 +1,47 @@
+package rtxt
+
+import (
+	"bufio"
+	"compress/gzip"
+	"io"
+)
+
+type Compression int
+
+const (
+	NoCompression Compression = 0
+	Gzip          Compression = 1
+)
+
+// Decompress detects gzip input by its magic bytes and returns a reader of the
+// uncompressed content; other input is returned as is
+func Decompress(r io.Reader) (io.Reader, Compression, error) {
+	br := bufio.NewReader(r)
+	if b, err := br.Peek(2); err == nil && b[0] == 0x1f && b[1] == 0x8b {
+		gz, err := gzip.NewReader(br)
+		if err != nil {
+			return nil, Gzip, err
+		}
+		return gz, Gzip, nil
+	}
+	return br, NoCompression, nil
+}
+
+// Compress returns a writer that compresses to w. Closing it finishes the
+// compressed stream but does not close w.
+func Compress(w io.Writer, c Compression) io.WriteCloser {
+	switch c {
+	case Gzip:
+		return gzip.NewWriter(w)
+	default:
+		return nopCloser{w}
+	}
+}
+
+type nopCloser struct {
+	io.Writer
+}
+
+func (nopCloser) Close() error {
+	return nil
+}
@@ -0,0 +1,75 @@
+package rtxt
+
+import (
+	"bytes"
+	"compress/gzip"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestCompression(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "test.txt.gz")
+	gz, none := Gzip, NoCompression
+	if err := SaveLines(path, []string{"a", "東"}, Utf16, &SaveOptions{Compression: &gz}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	b, _ := os.ReadFile(path)
+	if len(b) < 2 || b[0] != 0x1f || b[1] != 0x8b {
+		t.Fatalf("expected gzip file, received %x", b)
+	}
+
+	d, err := Load(path)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if d.Text != "a\n東" || d.Encoding != Utf16 || d.Compression != Gzip {
+		t.Errorf("unexpected document %+v", d)
+	}
+
+	d.SetLines([]string{"b"})
+	if err := d.Save(path); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	r := NewReader(bytes.NewReader(mustRead(t, path)))
+	if line, err := r.ReadLine(); err != nil || line != "b" {
+		t.Errorf("expected b, received %q %v", line, err)
+	}
+	if c, _ := r.Compression(); c != Gzip {
+		t.Errorf("expected compression to be preserved")
+	}
+	if _, err := BuildIndex(bytes.NewReader(mustRead(t, path))); err == nil {
+		t.Errorf("expected compressed document not to be indexed")
+	}
+	if _, err := BuildIndex(bytes.NewReader([]byte{0x1f, 0x8b, 0x08})); err == nil {
+		t.Errorf("expected gzip header to be rejected")
+	}
+
+	if err := d.SaveWithOptions(path, &SaveOptions{Compression: &none}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if b := mustRead(t, path); !bytes.HasPrefix(b, []byte{0xfe, 0xff}) {
+		t.Errorf("expected uncompressed UTF-16, received %x", b)
+	}
+}
+
+func TestDecompressPlain(t *testing.T) {
+	var buf bytes.Buffer
+	gz := gzip.NewWriter(&buf)
+	WriteLines(gz, []string{"x"}, Utf8)
+	gz.Close()
+
+	for _, b := range [][]byte{buf.Bytes(), {0xef, 0xbb, 0xbf, 'x'}, {'x'}} {
+		if lines, err := ReadLines(bytes.NewReader(b)); err != nil || Join(lines) != "x" {
+			t.Errorf("expected x, received %q %v", lines, err)
+		}
+	}
+}
+
+func mustRead(t *testing.T, path string) []byte {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	return b
+}
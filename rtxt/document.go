@@ -0,0 +1,116 @@
+package rtxt
+
+import (
+	"bytes"
+	"io"
+	"io/fs"
+	"os"
+
+	"golang.org/x/text/encoding/unicode"
+)
+
+type ReliableTxtDocument struct {
+	Text        string
+	Encoding    ReliableTxtEncoding
+	Compression Compression
+	// NoBom is set for a UTF-8 document read without a byte order mark, which
+	// is then saved without one too
+	NoBom bool
+}
+
+func NewDocument(text string, enc ReliableTxtEncoding) *ReliableTxtDocument {
+	return &ReliableTxtDocument{
+		Text:     text,
+		Encoding: enc,
+	}
+}
+
+func FromBytes(b []byte) (*ReliableTxtDocument, error) {
+	return ReadDocument(bytes.NewReader(b))
+}
+
+func ReadDocument(r io.Reader) (*ReliableTxtDocument, error) {
+	r, c, err := Decompress(r)
+	if err != nil {
+		return nil, err
+	}
+	enc, bom, d, err := detectEncoding(r, false)
+	if err != nil {
+		return nil, err
+	}
+	text, err := io.ReadAll(d)
+	if err != nil {
+		return nil, err
+	}
+	doc := NewDocument(string(text), enc)
+	doc.Compression = c
+	doc.NoBom = !bom
+	return doc, nil
+}
+
+func Load(path string) (*ReliableTxtDocument, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return ReadDocument(f)
+}
+
+func LoadFS(fsys fs.FS, name string) (*ReliableTxtDocument, error) {
+	f, err := fsys.Open(name)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return ReadDocument(f)
+}
+
+// LoadGlobFS loads every file in fsys matching pattern with load and returns
+// the results by file name; a failed file is reported as an fs.PathError
+func LoadGlobFS[T any](fsys fs.FS, pattern string, load func(fsys fs.FS, name string) (T, error)) (map[string]T, error) {
+	names, err := fs.Glob(fsys, pattern)
+	if err != nil {
+		return nil, err
+	}
+	result := make(map[string]T, len(names))
+	for _, name := range names {
+		v, err := load(fsys, name)
+		if err != nil {
+			return result, &fs.PathError{Op: "parse", Path: name, Err: err}
+		}
+		result[name] = v
+	}
+	return result, nil
+}
+
+func (d *ReliableTxtDocument) GetLines() []string {
+	return Split(d.Text)
+}
+func (d *ReliableTxtDocument) SetLines(lines []string) {
+	d.Text = Join(lines)
+}
+
+func (d *ReliableTxtDocument) ToBytes() ([]byte, error) {
+	if d.NoBom && d.Encoding == Utf8 {
+		return unicode.UTF8.NewEncoder().Bytes([]byte(d.Text))
+	}
+	return encoding(d.Encoding).NewEncoder().Bytes([]byte(d.Text))
+}
+
+func (d *ReliableTxtDocument) Save(path string) error {
+	return d.SaveWithOptions(path, nil)
+}
+
+// SaveWithOptions saves the document; it is compressed with d.Compression unless
+// opts sets a compression
+func (d *ReliableTxtDocument) SaveWithOptions(path string, opts *SaveOptions) error {
+	b, err := d.ToBytes()
+	if err != nil {
+		return err
+	}
+	return WriteFile(path, opts.WithCompression(d.Compression), func(w io.Writer) error {
+		_, err := w.Write(b)
+		return err
+	})
+}
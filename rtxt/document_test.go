@@ -0,0 +1,121 @@
+package rtxt
+
+import (
+	"bytes"
+	"encoding/hex"
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"testing"
+	"testing/fstest"
+)
+
+func TestDocumentBytes(t *testing.T) {
+	type test struct {
+		hex   string
+		enc   ReliableTxtEncoding
+		lines []string
+	}
+	tests := []test{
+		{"efbbbf", Utf8, []string{""}},
+		{"efbbbf610a62", Utf8, []string{"a", "b"}},
+		{"feff0061000a0062", Utf16, []string{"a", "b"}},
+		{"fffe61000a006200", Utf16Reverse, []string{"a", "b"}},
+		{"0000feff000000610000000a00000062", Utf32, []string{"a", "b"}},
+		{"0000feff0000feff", Utf32, []string{"\uFEFF"}},
+		{"", Utf8, []string{""}},
+		{"610a62", Utf8, []string{"a", "b"}},
+	}
+	for i, test := range tests {
+		b, _ := hex.DecodeString(test.hex)
+		d, err := FromBytes(b)
+		if err != nil {
+			t.Errorf("%v: unexpected error: %v", i, err)
+			continue
+		}
+		if d.Encoding != test.enc {
+			t.Errorf("%v: expected %v, received %v", i, test.enc, d.Encoding)
+		}
+		if Join(d.GetLines()) != Join(test.lines) {
+			t.Errorf("%v: expected %q, received %q", i, test.lines, d.GetLines())
+		}
+		if x, err := d.ToBytes(); err != nil {
+			t.Errorf("%v: unexpected error: %v", i, err)
+		} else if !bytes.Equal(x, b) {
+			t.Errorf("%v: expected %x, received %x", i, b, x)
+		}
+	}
+}
+
+func TestDocumentLoadSave(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "test.txt")
+	for _, enc := range []ReliableTxtEncoding{Utf8, Utf16, Utf16Reverse, Utf32} {
+		d := NewDocument("", enc)
+		d.SetLines([]string{"a", "東", "𝄞"})
+		if err := d.Save(path); err != nil {
+			t.Fatalf("%v: unexpected error: %v", enc, err)
+		}
+		l, err := Load(path)
+		if err != nil {
+			t.Fatalf("%v: unexpected error: %v", enc, err)
+		}
+		if l.Encoding != enc || l.Text != d.Text {
+			t.Errorf("%v: expected %q, received %v %q", enc, d.Text, l.Encoding, l.Text)
+		}
+	}
+
+	os.WriteFile(path, []byte("a\nb"), 0644)
+	d, err := Load(path)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !d.NoBom {
+		t.Errorf("expected a document without a byte order mark")
+	}
+	d.SetLines([]string{"c"})
+	if err := d.Save(path); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if b, _ := os.ReadFile(path); string(b) != "c" {
+		t.Errorf("expected %q, received %q", "c", b)
+	}
+}
+
+func TestDocumentLoadFS(t *testing.T) {
+	fsys := fstest.MapFS{
+		"conf/a.txt": {Data: []byte{0xfe, 0xff, 0x00, 'a'}},
+	}
+	d, err := LoadFS(fsys, "conf/a.txt")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if d.Text != "a" || d.Encoding != Utf16 {
+		t.Errorf("unexpected document %+v", d)
+	}
+	if _, err := LoadFS(fsys, "conf/b.txt"); !errors.Is(err, fs.ErrNotExist) {
+		t.Errorf("expected not exist error, received %v", err)
+	}
+}
+
+func TestLoadGlobFS(t *testing.T) {
+	fsys := fstest.MapFS{
+		"conf/a.txt": {Data: []byte("a")},
+		"conf/b.txt": {Data: []byte("b")},
+		"conf/c.bin": {Data: []byte{0x1f, 0x8b}},
+	}
+	docs, err := LoadGlobFS(fsys, "conf/*.txt", LoadFS)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(docs) != 2 || docs["conf/a.txt"].Text != "a" || docs["conf/b.txt"].Text != "b" {
+		t.Errorf("unexpected documents %v", docs)
+	}
+	var pe *fs.PathError
+	if _, err := LoadGlobFS(fsys, "conf/*", LoadFS); !errors.As(err, &pe) || pe.Path != "conf/c.bin" {
+		t.Errorf("expected error for conf/c.bin, received %v", err)
+	}
+	if _, err := LoadGlobFS(fsys, "[", LoadFS); err == nil {
+		t.Errorf("expected error for bad pattern")
+	}
+}
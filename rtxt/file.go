@@ -0,0 +1,196 @@
+package rtxt
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+)
+
+type SaveOptions struct {
+	// Backup keeps the previous version of the file as path + ".bak"
+	Backup bool
+	// Perm is used when the file does not exist yet; 0 means 0644
+	Perm fs.FileMode
+	// Compression compresses the written file when set; documents use their
+	// own compression when it is nil
+	Compression *Compression
+}
+
+// WithCompression returns a copy of o, which may be nil, that compresses with c
+// unless o sets a compression
+func (o *SaveOptions) WithCompression(c Compression) *SaveOptions {
+	r := SaveOptions{}
+	if o != nil {
+		r = *o
+	}
+	if r.Compression == nil {
+		r.Compression = &c
+	}
+	return &r
+}
+
+// WriteFile replaces the file at path with the output of fn. The output is
+// written to a temporary file in the same directory, synced, and renamed over
+// path, so a crash leaves either the old or the new file but never a partial one.
+func WriteFile(path string, opts *SaveOptions, fn func(w io.Writer) error) (err error) {
+	if opts == nil {
+		opts = &SaveOptions{}
+	}
+	perm := opts.Perm
+	if perm == 0 {
+		perm = 0644
+	}
+	info, err := os.Stat(path)
+	exists := err == nil
+	if exists {
+		perm = info.Mode().Perm()
+	} else if !errors.Is(err, fs.ErrNotExist) {
+		return err
+	}
+
+	dir, name := filepath.Split(path)
+	if dir == "" {
+		dir = "."
+	}
+	f, err := os.CreateTemp(dir, "."+name+".*.tmp")
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err != nil {
+			f.Close()
+			os.Remove(f.Name())
+		}
+	}()
+
+	compression := NoCompression
+	if opts.Compression != nil {
+		compression = *opts.Compression
+	}
+	c := Compress(f, compression)
+	if err = fn(c); err != nil {
+		return err
+	}
+	if err = c.Close(); err != nil {
+		return err
+	}
+	if err = f.Chmod(perm); err != nil {
+		return err
+	}
+	if err = f.Sync(); err != nil {
+		return err
+	}
+	if err = f.Close(); err != nil {
+		return err
+	}
+	if exists && opts.Backup {
+		if err = backup(path); err != nil {
+			return err
+		}
+	}
+	if err = os.Rename(f.Name(), path); err != nil {
+		return err
+	}
+	syncDir(dir)
+	return nil
+}
+
+func SaveLines(path string, lines []string, enc ReliableTxtEncoding, opts *SaveOptions) error {
+	return WriteFile(path, opts, func(w io.Writer) error {
+		_, err := WriteLines(w, lines, enc)
+		return err
+	})
+}
+
+// backup links (or failing that, copies) path to path + ".bak", so that path
+// itself stays in place until it is replaced
+func backup(path string) error {
+	bak := path + ".bak"
+	if err := os.Remove(bak); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return err
+	}
+	if err := os.Link(path, bak); err == nil {
+		return nil
+	}
+	src, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer src.Close()
+	info, err := src.Stat()
+	if err != nil {
+		return err
+	}
+	dst, err := os.OpenFile(bak, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, info.Mode().Perm())
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(dst, src); err != nil {
+		dst.Close()
+		return err
+	}
+	if err := dst.Sync(); err != nil {
+		dst.Close()
+		return err
+	}
+	return dst.Close()
+}
+
+// syncDir makes the rename durable; not every platform supports syncing a
+// directory so errors are ignored
+func syncDir(dir string) {
+	if d, err := os.Open(dir); err == nil {
+		d.Sync()
+		d.Close()
+	}
+}
+
+// AppendLines appends lines to the file at path in the file's own encoding.
+// A line feed separates them from existing content and no byte order mark is
+// written unless the file is new or empty, in which case enc is used.
+func AppendLines(path string, lines []string, enc ReliableTxtEncoding) (n int, err error) {
+	f, err := os.OpenFile(path, os.O_RDWR|os.O_APPEND|os.O_CREATE, 0644)
+	if err != nil {
+		return 0, err
+	}
+	defer func() {
+		if cerr := f.Close(); err == nil {
+			err = cerr
+		}
+	}()
+
+	info, err := f.Stat()
+	if err != nil {
+		return 0, err
+	}
+	w := NewWriter(f, enc)
+	if info.Size() > 0 {
+		head := make([]byte, 4)
+		c, err := f.ReadAt(head, 0)
+		if err != nil && err != io.EOF {
+			return 0, err
+		}
+		head = head[:c]
+		if len(head) >= 2 && head[0] == 0x1f && head[1] == 0x8b {
+			return 0, fmt.Errorf("Cannot append to a compressed file")
+		}
+		var size int
+		w.enc, size = detectBom(head)
+		w.started = true
+		if info.Size() > int64(size) {
+			w.lines = 1
+		}
+	}
+	for _, l := range lines {
+		if _, err := w.WriteLine(l); err != nil {
+			return int(w.Written()), err
+		}
+	}
+	if err := w.Close(); err != nil {
+		return int(w.Written()), err
+	}
+	return int(w.Written()), f.Sync()
+}
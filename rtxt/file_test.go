@@ -0,0 +1,127 @@
+package rtxt
+
+import (
+	"bytes"
+	"encoding/hex"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestWriteFile(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "test.txt")
+
+	if err := NewDocument("a", Utf8).Save(path); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if _, err := os.Stat(path + ".bak"); err == nil {
+		t.Errorf("expected no backup without Backup option")
+	}
+
+	if err := NewDocument("b", Utf8).SaveWithOptions(path, &SaveOptions{Backup: true}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if d, err := Load(path); err != nil || d.Text != "b" {
+		t.Errorf("expected b, received %v %v", d, err)
+	}
+	if d, err := Load(path + ".bak"); err != nil || d.Text != "a" {
+		t.Errorf("expected backup a, received %v %v", d, err)
+	}
+
+	failure := errors.New("failure")
+	err := WriteFile(path, nil, func(w io.Writer) error {
+		io.WriteString(w, "partial")
+		return failure
+	})
+	if err != failure {
+		t.Errorf("expected %v, received %v", failure, err)
+	}
+	if d, err := Load(path); err != nil || d.Text != "b" {
+		t.Errorf("expected b to survive a failed write, received %v %v", d, err)
+	}
+	if entries, _ := os.ReadDir(dir); len(entries) != 2 {
+		t.Errorf("expected temporary file to be removed, found %v", entries)
+	}
+}
+
+func TestSaveOptionsWithCompression(t *testing.T) {
+	gz, none := Gzip, NoCompression
+	tests := []struct {
+		opts     *SaveOptions
+		expected Compression
+	}{
+		{nil, Gzip},
+		{&SaveOptions{Backup: true}, Gzip},
+		{&SaveOptions{Compression: &none}, NoCompression},
+		{&SaveOptions{Compression: &gz}, Gzip},
+	}
+	for i, test := range tests {
+		o := test.opts.WithCompression(Gzip)
+		if *o.Compression != test.expected {
+			t.Errorf("%v: expected %v, received %v", i, test.expected, *o.Compression)
+		}
+		if test.opts != nil && (o == test.opts || o.Backup != test.opts.Backup) {
+			t.Errorf("%v: expected a copy of the options", i)
+		}
+	}
+}
+
+func TestSaveLines(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "test.txt")
+	if err := SaveLines(path, []string{"a", "b"}, Utf16, nil); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if d, err := Load(path); err != nil || d.Text != "a\nb" || d.Encoding != Utf16 {
+		t.Errorf("expected a\\nb, received %v %v", d, err)
+	}
+}
+
+func TestAppendLines(t *testing.T) {
+	type test struct {
+		initial []byte
+		enc     ReliableTxtEncoding
+		lines   []string
+		hex     string
+	}
+	tests := []test{
+		{nil, Utf16, []string{"a", "b"}, "feff0061000a0062"},
+		{[]byte{}, Utf8, []string{"a"}, "efbbbf61"},
+		{[]byte{0xef, 0xbb, 0xbf}, Utf16, []string{"a"}, "efbbbf61"},
+		{[]byte{0xef, 0xbb, 0xbf, 'x'}, Utf16, []string{"a", "b"}, "efbbbf780a610a62"},
+		{[]byte{0xfe, 0xff, 0x00, 'x'}, Utf8, []string{"a"}, "feff0078000a0061"},
+		{[]byte{0xfe, 0xff}, Utf8, nil, "feff"},
+		{[]byte{0x00, 0x00, 0xfe, 0xff}, Utf8, []string{"", ""}, "0000feff0000000a"},
+		{[]byte{'x'}, Utf16, []string{"a"}, "780a61"},
+	}
+	for i, test := range tests {
+		path := filepath.Join(t.TempDir(), "test.txt")
+		if test.initial != nil {
+			os.WriteFile(path, test.initial, 0644)
+		}
+		expected, _ := hex.DecodeString(test.hex)
+		n, err := AppendLines(path, test.lines, test.enc)
+		if err != nil {
+			t.Errorf("%v: unexpected error: %v", i, err)
+			continue
+		}
+		b, _ := os.ReadFile(path)
+		if !bytes.Equal(b, expected) {
+			t.Errorf("%v: expected %x, received %x", i, expected, b)
+		}
+		if n != len(expected)-len(test.initial) {
+			t.Errorf("%v: expected %v bytes, received %v", i, len(expected)-len(test.initial), n)
+		}
+	}
+}
+
+func TestAppendCompressed(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "test.txt.gz")
+	gz := Gzip
+	SaveLines(path, []string{"a"}, Utf8, &SaveOptions{Compression: &gz})
+	if _, err := AppendLines(path, []string{"b"}, Utf8); err == nil {
+		t.Errorf("expected error appending to a compressed file")
+	}
+}
@@ -0,0 +1,68 @@
+package rtxt
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"slices"
+	"testing"
+	"unicode/utf8"
+)
+
+func FuzzReader(f *testing.F) {
+	f.Add([]byte("a\nb"))
+	f.Add([]byte("\xef\xbb\xbfa\r\nb"))
+	f.Add([]byte("\xfe\xff\x00a\x00\n"))
+	f.Add([]byte("\x00\x00\xfe\xff\x00\x00\x00a"))
+	f.Add([]byte("\xff\xfe\x00\xd8"))
+	f.Fuzz(func(t *testing.T, data []byte) {
+		for _, strict := range []bool{false, true} {
+			r := NewReader(bytes.NewReader(data))
+			r.Strict = strict
+			for i := 0; ; i++ {
+				_, err := r.ReadLine()
+				if err == io.EOF {
+					break
+				} else if err != nil {
+					// gzip input may also fail to decompress
+					var de *DecodeError
+					if strict && !bytes.HasPrefix(data, []byte{0x1f, 0x8b}) && !errors.As(err, &de) {
+						t.Fatalf("expected DecodeError, got %v", err)
+					}
+					break
+				}
+				if r.Line() != i {
+					t.Fatalf("expected line %v, got %v", i, r.Line())
+				}
+			}
+		}
+	})
+}
+
+func FuzzRoundTrip(f *testing.F) {
+	f.Add("", byte(0))
+	f.Add("a\nb", byte(1))
+	f.Add("\n\n", byte(2))
+	f.Add("𝄞\nä", byte(3))
+	f.Fuzz(func(t *testing.T, s string, e byte) {
+		if !utf8.ValidString(s) {
+			return
+		}
+		enc := ReliableTxtEncoding(int(e) % 4)
+		lines := Split(s)
+		var b bytes.Buffer
+		if _, err := WriteLines(&b, lines, enc); err != nil {
+			t.Fatalf("unexpected error %v", err)
+		}
+		if err := Validate(bytes.NewReader(b.Bytes())); err != nil && !errors.Is(err, ErrStrayBom) {
+			t.Fatalf("unexpected error %v", err)
+		}
+		got, err := ReadLines(&b)
+		if err != nil {
+			t.Fatalf("unexpected error %v", err)
+		}
+		if !slices.Equal(got, lines) {
+			t.Fatalf("%v: expected %q, got %q", enc, lines, got)
+		}
+	})
+}
@@ -0,0 +1,204 @@
+package rtxt
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+)
+
+var indexMagic = []byte("RTXTIDX\x01")
+
+// LineIndex records the byte offset of every line of a document so that lines
+// can be read without scanning from the start.
+type LineIndex struct {
+	Encoding ReliableTxtEncoding
+	// Size is the length in bytes of the indexed document
+	Size    int64
+	ModTime int64
+	offsets []int64
+}
+
+func BuildIndex(r io.Reader) (*LineIndex, error) {
+	rd := NewReader(r)
+	if c, err := rd.Compression(); err != nil {
+		return nil, err
+	} else if c != NoCompression {
+		return nil, fmt.Errorf("Cannot index a compressed document")
+	}
+	x := &LineIndex{offsets: make([]int64, 0)}
+	for {
+		if _, err := rd.ReadLineBytes(); err == io.EOF {
+			break
+		} else if err != nil {
+			return nil, err
+		}
+		x.offsets = append(x.offsets, rd.Offset())
+	}
+	x.Encoding = rd.enc
+	x.Size = rd.pos
+	return x, nil
+}
+
+// Len returns the number of lines in the document
+func (x *LineIndex) Len() int {
+	return len(x.offsets)
+}
+
+// Offset returns the byte offset of line i
+func (x *LineIndex) Offset(i int) int64 {
+	return x.offsets[i]
+}
+
+func (x *LineIndex) ReadLine(r io.ReaderAt, i int) (string, error) {
+	lines, err := x.ReadLineRange(r, i, i+1)
+	if err != nil {
+		return "", err
+	}
+	return lines[0], nil
+}
+
+// ReadLineRange returns lines start (inclusive) to end (exclusive)
+func (x *LineIndex) ReadLineRange(r io.ReaderAt, start, end int) ([]string, error) {
+	if start < 0 || end > len(x.offsets) || start > end {
+		return nil, fmt.Errorf("Line range %v:%v out of bounds for %v lines", start, end, len(x.offsets))
+	}
+	lines := make([]string, 0, end-start)
+	if start == end {
+		return lines, nil
+	}
+	size := x.Size - x.offsets[start]
+	if end < len(x.offsets) {
+		size = x.offsets[end] - x.offsets[start] - int64(unitSize(x.Encoding))
+	}
+	rd := &Reader{
+		r:       bufio.NewReader(io.NewSectionReader(r, x.offsets[start], size)),
+		enc:     x.Encoding,
+		started: true,
+		line:    start - 1,
+		pos:     x.offsets[start],
+	}
+	for len(lines) < end-start {
+		line, err := rd.ReadLine()
+		if err == io.EOF {
+			return lines, io.ErrUnexpectedEOF
+		} else if err != nil {
+			return lines, err
+		}
+		lines = append(lines, line)
+	}
+	return lines, nil
+}
+
+// WriteTo writes the index in a compact binary form that ReadIndex understands
+func (x *LineIndex) WriteTo(w io.Writer) (int64, error) {
+	b := make([]byte, 0, len(indexMagic)+1+3*binary.MaxVarintLen64+2*len(x.offsets))
+	b = append(b, indexMagic...)
+	b = append(b, byte(x.Encoding))
+	b = binary.AppendUvarint(b, uint64(x.Size))
+	b = binary.AppendVarint(b, x.ModTime)
+	b = binary.AppendUvarint(b, uint64(len(x.offsets)))
+	var prev int64
+	for _, o := range x.offsets {
+		b = binary.AppendUvarint(b, uint64(o-prev))
+		prev = o
+	}
+	n, err := w.Write(b)
+	return int64(n), err
+}
+
+func ReadIndex(r io.Reader) (*LineIndex, error) {
+	br := bufio.NewReader(r)
+	magic := make([]byte, len(indexMagic))
+	if _, err := io.ReadFull(br, magic); err != nil || !bytes.Equal(magic, indexMagic) {
+		return nil, fmt.Errorf("Not a line index")
+	}
+	enc, err := br.ReadByte()
+	if err != nil {
+		return nil, err
+	}
+	size, err := binary.ReadUvarint(br)
+	if err != nil {
+		return nil, err
+	}
+	modTime, err := binary.ReadVarint(br)
+	if err != nil {
+		return nil, err
+	}
+	count, err := binary.ReadUvarint(br)
+	if err != nil {
+		return nil, err
+	}
+	if count > size+1 {
+		return nil, fmt.Errorf("Invalid line count %v for size %v", count, size)
+	}
+	x := &LineIndex{
+		Encoding: ReliableTxtEncoding(enc),
+		Size:     int64(size),
+		ModTime:  modTime,
+		offsets:  make([]int64, count),
+	}
+	var prev int64
+	for i := range x.offsets {
+		d, err := binary.ReadUvarint(br)
+		if err != nil {
+			return nil, err
+		}
+		prev += int64(d)
+		x.offsets[i] = prev
+	}
+	return x, nil
+}
+
+// IndexFile returns the index of the file at path. The index is kept in a
+// sidecar file path + ".idx", which is rebuilt when missing or out of date.
+func IndexFile(path string) (*LineIndex, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, err
+	}
+	if f, err := os.Open(path + ".idx"); err == nil {
+		x, err := ReadIndex(f)
+		f.Close()
+		if err == nil && x.Size == info.Size() && x.ModTime == info.ModTime().UnixNano() {
+			return x, nil
+		}
+	} else if !errors.Is(err, fs.ErrNotExist) {
+		return nil, err
+	}
+
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	x, err := BuildIndex(f)
+	if err != nil {
+		return nil, err
+	}
+	x.ModTime = info.ModTime().UnixNano()
+	err = WriteFile(path+".idx", nil, func(w io.Writer) error {
+		_, err := x.WriteTo(w)
+		return err
+	})
+	return x, err
+}
+
+// ReadLineRange reads lines start (inclusive) to end (exclusive) of the file at
+// path using its sidecar index
+func ReadLineRange(path string, start, end int) ([]string, error) {
+	x, err := IndexFile(path)
+	if err != nil {
+		return nil, err
+	}
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return x.ReadLineRange(f, start, end)
+}
@@ -0,0 +1,61 @@
+package rtxt
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestLineIndex(t *testing.T) {
+	lines := []string{"a", "", "東京", "𝄞 x", "last"}
+	for _, enc := range []ReliableTxtEncoding{Utf8, Utf16, Utf16Reverse, Utf32} {
+		var buf bytes.Buffer
+		WriteLines(&buf, lines, enc)
+		b := buf.Bytes()
+
+		x, err := BuildIndex(bytes.NewReader(b))
+		if err != nil {
+			t.Fatalf("%v: unexpected error: %v", enc, err)
+		}
+		if x.Len() != len(lines) || x.Size != int64(len(b)) || x.Encoding != enc {
+			t.Fatalf("%v: unexpected index %+v", enc, x)
+		}
+
+		var persisted bytes.Buffer
+		x.WriteTo(&persisted)
+		if x, err = ReadIndex(&persisted); err != nil {
+			t.Fatalf("%v: unexpected error: %v", enc, err)
+		}
+
+		for start := 0; start <= len(lines); start++ {
+			for end := start; end <= len(lines); end++ {
+				r, err := x.ReadLineRange(bytes.NewReader(b), start, end)
+				if err != nil {
+					t.Errorf("%v: %v:%v: unexpected error: %v", enc, start, end, err)
+				} else if Join(r) != Join(lines[start:end]) || len(r) != end-start {
+					t.Errorf("%v: %v:%v: expected %q, received %q", enc, start, end, lines[start:end], r)
+				}
+			}
+		}
+		if _, err := x.ReadLineRange(bytes.NewReader(b), 2, 6); err == nil {
+			t.Errorf("%v: expected out of bounds error", enc)
+		}
+	}
+}
+
+func TestIndexFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "test.txt")
+	SaveLines(path, []string{"a", "b", "c"}, Utf8, nil)
+	if lines, err := ReadLineRange(path, 1, 3); err != nil || Join(lines) != "b\nc" {
+		t.Errorf("expected b c, received %q %v", lines, err)
+	}
+	if _, err := os.Stat(path + ".idx"); err != nil {
+		t.Errorf("expected sidecar index: %v", err)
+	}
+
+	SaveLines(path, []string{"x", "yy", "zzz", "w"}, Utf16, nil)
+	if lines, err := ReadLineRange(path, 2, 4); err != nil || Join(lines) != "zzz\nw" {
+		t.Errorf("expected stale index to be rebuilt, received %q %v", lines, err)
+	}
+}
@@ -0,0 +1,121 @@
+package rtxt
+
+import (
+	"bytes"
+	"errors"
+	"unicode/utf8"
+)
+
+var ErrCarriageReturn = errors.New("Carriage return in line")
+
+// LineEndingPolicy decides how a Reader treats carriage returns. ReliableTXT
+// only knows the line feed, so a carriage return is otherwise part of the line.
+type LineEndingPolicy int
+
+const (
+	// PreserveLineEndings keeps carriage returns as part of the line
+	PreserveLineEndings LineEndingPolicy = 0
+	// NormalizeLineEndings reads CR LF and a lone CR as a line feed
+	NormalizeLineEndings LineEndingPolicy = 1
+	// StrictLineEndings reports a carriage return as a DecodeError
+	StrictLineEndings LineEndingPolicy = 2
+)
+
+func (p LineEndingPolicy) String() string {
+	switch p {
+	case NormalizeLineEndings:
+		return "normalize"
+	case StrictLineEndings:
+		return "strict"
+	default:
+		return "preserve"
+	}
+}
+
+// LineEndingReport tells which policy a Reader applied and how many carriage
+// returns it came across
+type LineEndingReport struct {
+	Policy LineEndingPolicy
+	// CRLF counts carriage returns directly before a line feed
+	CRLF int
+	// CR counts any other carriage returns
+	CR int
+}
+
+// LineEndingReport returns the carriage returns seen in the lines read so far
+func (r *Reader) LineEndingReport() LineEndingReport {
+	report := r.lineEndings
+	report.Policy = r.LineEndings
+	return report
+}
+
+type pendingLine struct {
+	b      []byte
+	offset int64
+}
+
+// applyLineEndings applies the line ending policy to the decoded line b, which
+// was ended by a line feed if eol is set
+func (r *Reader) applyLineEndings(b []byte, eol bool) ([]byte, error) {
+	i := bytes.IndexByte(b, '\r')
+	if i < 0 {
+		return b, nil
+	}
+	switch r.LineEndings {
+	case StrictLineEndings:
+		return nil, r.errorAt(r.rawIndex(utf8.RuneCount(b[:i])), ErrCarriageReturn)
+	case NormalizeLineEndings:
+		if eol && b[len(b)-1] == '\r' {
+			b = b[:len(b)-1]
+			r.lineEndings.CRLF++
+		}
+		segments := bytes.Split(b, []byte{'\r'})
+		col := 0
+		for j, s := range segments {
+			col += utf8.RuneCount(s)
+			if j+1 < len(segments) {
+				r.lineEndings.CR++
+				offset := r.offset + int64(r.rawIndex(col)+unitSize(r.enc))
+				r.pending = append(r.pending, pendingLine{segments[j+1], offset})
+				col++
+			}
+		}
+		return segments[0], nil
+	default:
+		for rest := b; i >= 0; i = bytes.IndexByte(rest, '\r') {
+			if eol && i == len(rest)-1 {
+				r.lineEndings.CRLF++
+			} else {
+				r.lineEndings.CR++
+			}
+			rest = rest[i+1:]
+		}
+		return b, nil
+	}
+}
+
+// rawIndex returns the byte index in the raw line of code point col
+func (r *Reader) rawIndex(col int) int {
+	switch r.enc {
+	case Utf16, Utf16Reverse:
+		i := 0
+		for ; col > 0 && i+1 < len(r.raw); col-- {
+			if u := r.unit16(i); u >= 0xd800 && u < 0xdc00 && i+3 < len(r.raw) {
+				if l := r.unit16(i + 2); l >= 0xdc00 && l < 0xe000 {
+					i += 2
+				}
+			}
+			i += 2
+		}
+		return i
+	case Utf32:
+		return col * 4
+	default:
+		i := 0
+		for ; col > 0 && i < len(r.raw); col-- {
+			_, n := utf8.DecodeRune(r.raw[i:])
+			i += n
+		}
+		return i
+	}
+}
@@ -0,0 +1,66 @@
+package rtxt
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+)
+
+func TestLineEndings(t *testing.T) {
+	type test struct {
+		text      string
+		policy    LineEndingPolicy
+		lines     []string
+		offsets   []int64
+		crlf, cr  int
+		errColumn int
+	}
+	tests := []test{
+		{"a\r\nb", PreserveLineEndings, []string{"a\r", "b"}, []int64{3, 6}, 1, 0, 0},
+		{"a\rb\r", PreserveLineEndings, []string{"a\rb\r"}, []int64{3}, 0, 2, 0},
+		{"a\r\nb", NormalizeLineEndings, []string{"a", "b"}, []int64{3, 6}, 1, 0, 0},
+		{"a\rb\r\nc", NormalizeLineEndings, []string{"a", "b", "c"}, []int64{3, 5, 8}, 1, 1, 0},
+		{"東\r\r𝄞\r", NormalizeLineEndings, []string{"東", "", "𝄞", ""}, []int64{3, 7, 8, 13}, 0, 3, 0},
+		{"\r\n\r\n", NormalizeLineEndings, []string{"", "", ""}, []int64{3, 5, 7}, 2, 0, 0},
+		{"ab\n", StrictLineEndings, []string{"ab", ""}, []int64{3, 6}, 0, 0, 0},
+		{"ab\n東c\r\n", StrictLineEndings, []string{"ab"}, []int64{3}, 0, 0, 2},
+	}
+	for _, enc := range []ReliableTxtEncoding{Utf8, Utf16, Utf16Reverse, Utf32} {
+		for i, test := range tests {
+			var buf bytes.Buffer
+			WriteLines(&buf, []string{test.text}, enc)
+			r := NewReader(&buf)
+			r.LineEndings = test.policy
+			lines := make([]string, 0)
+			var err error
+			for {
+				var line string
+				if line, err = r.ReadLine(); err != nil {
+					break
+				}
+				lines = append(lines, line)
+				if enc == Utf8 && r.Offset() != test.offsets[r.Line()] {
+					t.Errorf("%v %v: line %v: expected offset %v, received %v", enc, i, r.Line(), test.offsets[r.Line()], r.Offset())
+				}
+			}
+			if strings.Join(lines, "|") != strings.Join(test.lines, "|") {
+				t.Errorf("%v %v: expected %q, received %q", enc, i, test.lines, lines)
+			}
+			if test.errColumn > 0 {
+				var de *DecodeError
+				if !errors.As(err, &de) || !errors.Is(err, ErrCarriageReturn) || de.Column != test.errColumn {
+					t.Errorf("%v %v: expected carriage return error at column %v, received %v", enc, i, test.errColumn, err)
+				}
+				continue
+			} else if err != io.EOF {
+				t.Errorf("%v %v: unexpected error: %v", enc, i, err)
+			}
+			report := r.LineEndingReport()
+			if report.Policy != test.policy || report.CRLF != test.crlf || report.CR != test.cr {
+				t.Errorf("%v %v: unexpected report %+v", enc, i, report)
+			}
+		}
+	}
+}
@@ -2,11 +2,16 @@ package rtxt
 
 import (
 	"bufio"
+	"bytes"
+	"errors"
 	"fmt"
 	"io"
 	"strings"
+	"testing/iotest"
 
+	xencoding "golang.org/x/text/encoding"
 	"golang.org/x/text/encoding/unicode"
+	"golang.org/x/text/encoding/unicode/utf32"
 	"golang.org/x/text/transform"
 )
 
@@ -14,11 +19,31 @@ type ReliableTxtEncoding int
 
 const (
 	Utf8         ReliableTxtEncoding = 0
-	Utf16                            = 1
-	Utf16Reverse                     = 2
-	Utf32                            = 3
+	Utf16        ReliableTxtEncoding = 1
+	Utf16Reverse ReliableTxtEncoding = 2
+	Utf32        ReliableTxtEncoding = 3
 )
 
+var (
+	ErrMissingBom = errors.New("Missing byte order mark")
+	ErrInvalidBom = errors.New("Invalid byte order mark")
+)
+
+func (e ReliableTxtEncoding) String() string {
+	switch e {
+	case Utf8:
+		return "UTF-8"
+	case Utf16:
+		return "UTF-16"
+	case Utf16Reverse:
+		return "UTF-16 Reverse"
+	case Utf32:
+		return "UTF-32"
+	default:
+		return fmt.Sprintf("ReliableTxtEncoding(%d)", int(e))
+	}
+}
+
 func Join(lines []string) string {
 	return strings.Join(lines, "\n")
 }
@@ -27,58 +52,142 @@ func Split(s string) []string {
 }
 
 func Encoder(w io.Writer, enc ReliableTxtEncoding) (io.Writer, error) {
+	return transform.NewWriter(w, encoding(enc).NewEncoder().Transformer), nil
+}
+
+// encoding returns an encoding that writes the byte order mark for enc
+func encoding(enc ReliableTxtEncoding) xencoding.Encoding {
 	switch enc {
 	case Utf32:
-		return nil, fmt.Errorf("UTF32 encoding not implemented")
+		return utf32.UTF32(utf32.BigEndian, utf32.UseBOM)
 	case Utf16:
-		t := unicode.UTF16(unicode.BigEndian, unicode.UseBOM)
-		w = transform.NewWriter(w, t.NewEncoder().Transformer)
+		return unicode.UTF16(unicode.BigEndian, unicode.UseBOM)
 	case Utf16Reverse:
-		t := unicode.UTF16(unicode.LittleEndian, unicode.UseBOM)
-		w = transform.NewWriter(w, t.NewEncoder().Transformer)
+		return unicode.UTF16(unicode.LittleEndian, unicode.UseBOM)
 	default:
-		t := unicode.UTF8BOM
-		w = transform.NewWriter(w, t.NewEncoder().Transformer)
+		return unicode.UTF8BOM
 	}
-	return w, nil
 }
 
-func WriteLines(w io.Writer, lines []string, enc ReliableTxtEncoding) (n int, err error) {
-	var r int
-	e, err := Encoder(w, enc)
+// Decoder returns a reader that decodes r to UTF-8. The encoding is taken from
+// the byte order mark at the start of r; without one, UTF-8 is assumed. When r
+// cannot be read, the returned reader reports the error.
+func Decoder(r io.Reader) io.Reader {
+	_, d, err := DetectEncoding(r, false)
 	if err != nil {
-		return 0, err
+		return iotest.ErrReader(err)
+	}
+	return d
+}
+
+// DetectEncoding reads the byte order mark at the start of r and returns the
+// encoding it identifies along with a reader of the decoded text. When strict is
+// false a missing byte order mark is treated as UTF-8; when strict is true it is
+// reported as ErrMissingBom, or ErrInvalidBom if r starts with a partial one.
+func DetectEncoding(r io.Reader, strict bool) (ReliableTxtEncoding, io.Reader, error) {
+	enc, _, d, err := detectEncoding(r, strict)
+	return enc, d, err
+}
+
+// detectEncoding implements DetectEncoding and also reports whether r starts
+// with a byte order mark
+func detectEncoding(r io.Reader, strict bool) (ReliableTxtEncoding, bool, io.Reader, error) {
+	br := bufio.NewReader(r)
+	b, err := br.Peek(4)
+	if err != nil && err != io.EOF {
+		return Utf8, false, nil, err
+	}
+	enc, n := detectBom(b)
+	if n == 0 && strict {
+		if isPartialBom(b) {
+			return Utf8, false, nil, ErrInvalidBom
+		}
+		return Utf8, false, nil, ErrMissingBom
+	}
+	br.Discard(n)
+	return enc, n > 0, transform.NewReader(br, decoding(enc).NewDecoder()), nil
+}
+
+func detectBom(b []byte) (enc ReliableTxtEncoding, n int) {
+	switch {
+	case bytes.HasPrefix(b, bomUtf8):
+		return Utf8, 3
+	case bytes.HasPrefix(b, bomUtf32):
+		return Utf32, 4
+	case bytes.HasPrefix(b, bomUtf16):
+		return Utf16, 2
+	case bytes.HasPrefix(b, bomUtf16Reverse):
+		return Utf16Reverse, 2
 	}
-	for l := range lines {
-		if l > 0 {
-			if n, err := io.WriteString(e, "\n"); err == nil {
-				return n + r, err
-			} else {
-				n += r
-			}
+	return Utf8, 0
+}
+
+// isPartialBom reports whether b starts like a byte order mark but is cut short
+// or continues with the wrong bytes
+func isPartialBom(b []byte) bool {
+	for _, bom := range [][]byte{bomUtf8, bomUtf16, bomUtf16Reverse, bomUtf32} {
+		n := 0
+		for n < len(b) && n < len(bom) && b[n] == bom[n] {
+			n++
+		}
+		if n >= 2 || (n > 0 && n == len(b)) {
+			return true
 		}
-		if n, err = io.WriteString(e, lines[l]); err == nil {
-			return n + r, err
-		} else {
-			n += r
+	}
+	return false
+}
+
+var (
+	bomUtf8         = []byte{0xef, 0xbb, 0xbf}
+	bomUtf16        = []byte{0xfe, 0xff}
+	bomUtf16Reverse = []byte{0xff, 0xfe}
+	bomUtf32        = []byte{0x00, 0x00, 0xfe, 0xff}
+)
+
+// decoding returns an encoding that decodes without consuming a byte order mark,
+// so that a U+FEFF following the preamble is kept as text
+func decoding(enc ReliableTxtEncoding) xencoding.Encoding {
+	switch enc {
+	case Utf32:
+		return utf32.UTF32(utf32.BigEndian, utf32.IgnoreBOM)
+	case Utf16:
+		return unicode.UTF16(unicode.BigEndian, unicode.IgnoreBOM)
+	case Utf16Reverse:
+		return unicode.UTF16(unicode.LittleEndian, unicode.IgnoreBOM)
+	default:
+		return unicode.UTF8
+	}
+}
+
+// WriteLines writes lines as a ReliableTXT document and returns the number of
+// encoded bytes w accepted, including the byte order mark.
+func WriteLines(w io.Writer, lines []string, enc ReliableTxtEncoding) (n int, err error) {
+	e := NewWriter(w, enc)
+	for _, l := range lines {
+		if _, err := e.WriteLine(l); err != nil {
+			return int(e.Written()), err
 		}
 	}
-	return r, nil
+	err = e.Close()
+	return int(e.Written()), err
 }
 
 func ReadLines(r io.Reader) (lines []string, err error) {
 	res := make([]string, 0)
-	s := ScanLines(r)
-	for s.Scan() {
-		res = append(res, s.Text())
+	rd := NewReader(r)
+	for {
+		line, err := rd.ReadLine()
+		if err == io.EOF {
+			return res, nil
+		} else if err != nil {
+			return res, err
+		}
+		res = append(res, line)
 	}
-	return res, s.Err()
 }
 
 func ScanLines(r io.Reader) *bufio.Scanner {
-	t := unicode.BOMOverride(unicode.UTF8BOM.NewDecoder())
-	tr := transform.NewReader(r, t)
-	s := bufio.NewScanner(tr)
+	s := bufio.NewScanner(Decoder(r))
 	s.Split(func(data []byte, atEOF bool) (advance int, token []byte, err error) {
 		if atEOF && len(data) == 0 {
 			return 0, data, bufio.ErrFinalToken
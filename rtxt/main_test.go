@@ -3,9 +3,11 @@ package rtxt
 import (
 	"bytes"
 	"encoding/hex"
+	"errors"
 	"io"
 	"strings"
 	"testing"
+	"testing/iotest"
 )
 
 type Pair[T any] struct {
@@ -91,6 +93,33 @@ func TestEncodeUtf16Reverse(t *testing.T) {
 	}
 }
 
+func TestEncodeUtf32(t *testing.T) {
+	for _, p := range []Pair[string]{
+		{"", ""},
+		{"a", "00000061"},
+		{"a¥", "00000061000000A5"},
+		{"\uFEFF", "0000FEFF"},
+		{"\uFEFF\uFEFF", "0000FEFF0000FEFF"},
+		{"a\u6771", "0000006100006771"},
+		{"\u0000", "00000000"},
+		{"𝄞", "0001D11E"},
+	} {
+		var buf bytes.Buffer
+		bom, _ := hex.DecodeString("0000feff")
+		str, _ := hex.DecodeString(p.hex)
+		expected := append(bom[:], str[:]...)
+
+		w, _ := Encoder(&buf, Utf32)
+		_, err := io.WriteString(w, p.val)
+		if err != nil {
+			t.Errorf("Unexpected error: %v", err)
+		}
+		if !bytes.Equal(expected, buf.Bytes()) {
+			t.Errorf("Incorrect string; expected %v, received %v", expected, buf.Bytes())
+		}
+	}
+}
+
 func TestScanUtf8(t *testing.T) {
 	for _, p := range []Pair[string]{
 		// {"", ""},
@@ -150,7 +179,7 @@ func TestScanUtf16Reverse(t *testing.T) {
 		{"\u0000", "0000"},
 	} {
 		bom, _ := hex.DecodeString("fffe")
-		str, _ := hex.DecodeString("0a")
+		str, _ := hex.DecodeString(p.hex)
 		buf := bytes.NewBuffer(append(bom[:], str[:]...))
 
 		arr, err := ReadLines(buf)
@@ -163,6 +192,51 @@ func TestScanUtf16Reverse(t *testing.T) {
 	}
 }
 
+func TestScanUtf32(t *testing.T) {
+	for _, p := range []Pair[string]{
+		{"a", "00000061"},
+		{"a¥", "00000061000000A5"},
+		{"\uFEFF", "0000FEFF"},
+		{"\uFEFF\uFEFF", "0000FEFF0000FEFF"},
+		{"a\u6771", "0000006100006771"},
+		{"\u0000", "00000000"},
+		{"𝄞", "0001D11E"},
+	} {
+		bom, _ := hex.DecodeString("0000feff")
+		str, _ := hex.DecodeString(p.hex)
+		buf := bytes.NewBuffer(append(bom[:], str[:]...))
+
+		arr, err := ReadLines(buf)
+		if err != nil {
+			t.Errorf("Unexpected error: %v", err)
+		}
+		if !strings.EqualFold(arr[0], p.val) {
+			t.Errorf("Incorrect string; expected %v, received %v", p.val, arr[0])
+		}
+	}
+}
+
+func TestRoundTripEncodings(t *testing.T) {
+	lines := []string{"a", "", "a¥\u6771", "\uFEFF", "𝄞 \u0000"}
+	for _, enc := range []ReliableTxtEncoding{Utf8, Utf16, Utf16Reverse, Utf32} {
+		var buf bytes.Buffer
+		w, err := Encoder(&buf, enc)
+		if err != nil {
+			t.Fatalf("%v: unexpected error: %v", enc, err)
+		}
+		if _, err := io.WriteString(w, Join(lines)); err != nil {
+			t.Errorf("%v: unexpected error: %v", enc, err)
+		}
+		arr, err := ReadLines(&buf)
+		if err != nil {
+			t.Errorf("%v: unexpected error: %v", enc, err)
+		}
+		if Join(arr) != Join(lines) {
+			t.Errorf("%v: expected %q, received %q", enc, lines, arr)
+		}
+	}
+}
+
 func TestScanLinesUtf8(t *testing.T) {
 	for _, p := range []Pair[[]string]{
 		{[]string{}, ""},
@@ -186,3 +260,59 @@ func TestScanLinesUtf8(t *testing.T) {
 		}
 	}
 }
+
+func TestScanLinesError(t *testing.T) {
+	failed := errors.New("failed")
+	s := ScanLines(iotest.ErrReader(failed))
+	for s.Scan() {
+	}
+	if err := s.Err(); err != failed {
+		t.Errorf("expected error %v, received %v", failed, err)
+	}
+}
+
+func TestDetectEncoding(t *testing.T) {
+	type test struct {
+		hex    string
+		strict bool
+		enc    ReliableTxtEncoding
+		text   string
+		err    error
+	}
+	tests := []test{
+		{"efbbbf61", true, Utf8, "a", nil},
+		{"feff0061", true, Utf16, "a", nil},
+		{"fffe6100", true, Utf16Reverse, "a", nil},
+		{"0000feff00000061", true, Utf32, "a", nil},
+		{"efbbbf", true, Utf8, "", nil},
+		{"0000feff", true, Utf32, "", nil},
+		{"61", false, Utf8, "a", nil},
+		{"", false, Utf8, "", nil},
+		{"61", true, Utf8, "", ErrMissingBom},
+		{"", true, Utf8, "", ErrMissingBom},
+		{"efbb61", true, Utf8, "", ErrInvalidBom},
+		{"ef", true, Utf8, "", ErrInvalidBom},
+		{"0000fe00", true, Utf8, "", ErrInvalidBom},
+	}
+	for i, test := range tests {
+		b, _ := hex.DecodeString(test.hex)
+		enc, r, err := DetectEncoding(bytes.NewReader(b), test.strict)
+		if err != test.err {
+			t.Errorf("%v: expected error %v, received %v", i, test.err, err)
+			continue
+		}
+		if err != nil {
+			continue
+		}
+		if enc != test.enc {
+			t.Errorf("%v: expected %v, received %v", i, test.enc, enc)
+		}
+		text, err := io.ReadAll(r)
+		if err != nil {
+			t.Errorf("%v: unexpected error: %v", i, err)
+		}
+		if string(text) != test.text {
+			t.Errorf("%v: expected %q, received %q", i, test.text, text)
+		}
+	}
+}
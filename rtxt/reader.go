@@ -0,0 +1,362 @@
+package rtxt
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"unicode/utf16"
+	"unicode/utf8"
+)
+
+var (
+	ErrTruncated       = errors.New("Truncated code unit")
+	ErrInvalidSequence = errors.New("Invalid code unit sequence")
+	ErrLoneSurrogate   = errors.New("Unpaired surrogate")
+	ErrStrayBom        = errors.New("Byte order mark after start of document")
+	ErrLineTooLong     = errors.New("Line too long")
+)
+
+// DecodeError reports a problem decoding a line. Line and Column are 0-based;
+// Column counts code points. Offset is the byte offset in the encoded stream.
+type DecodeError struct {
+	Line   int
+	Column int
+	Offset int64
+	Err    error
+}
+
+func (e *DecodeError) Error() string {
+	return fmt.Sprintf("Line %v, column %v (byte %v): %v", e.Line, e.Column, e.Offset, e.Err)
+}
+
+func (e *DecodeError) Unwrap() error {
+	return e.Err
+}
+
+// Reader reads the lines of a ReliableTXT document. Unlike ScanLines there is no
+// limit on the length of a line. The encoding is detected from the byte order
+// mark; invalid code units are replaced with U+FFFD unless Strict is set.
+// Gzip compressed input is decompressed, in which case offsets refer to the
+// uncompressed document.
+type Reader struct {
+	// Strict rejects a missing byte order mark, invalid code unit sequences,
+	// unpaired surrogates and U+FEFF after the byte order mark with a DecodeError
+	Strict bool
+	// LineEndings decides how carriage returns are handled
+	LineEndings LineEndingPolicy
+	// MaxLineLength limits the encoded length of a line in bytes; 0 means no limit
+	MaxLineLength int
+
+	r           *bufio.Reader
+	enc         ReliableTxtEncoding
+	compression Compression
+	bom         bool
+	started     bool
+	done        bool
+	line        int
+	offset      int64
+	pos         int64
+	raw         []byte
+	buf         []byte
+	pending     []pendingLine
+	lineEndings LineEndingReport
+}
+
+func NewReader(r io.Reader) *Reader {
+	return &Reader{
+		r:    bufio.NewReader(r),
+		line: -1,
+	}
+}
+
+// Encoding returns the encoding of the document, reading the byte order mark if
+// no line has been read yet
+func (r *Reader) Encoding() (ReliableTxtEncoding, error) {
+	if err := r.start(); err != nil {
+		return Utf8, err
+	}
+	return r.enc, nil
+}
+
+// HasBom reports whether the document starts with a byte order mark, reading it
+// if no line has been read yet
+func (r *Reader) HasBom() (bool, error) {
+	if err := r.start(); err != nil {
+		return false, err
+	}
+	return r.bom, nil
+}
+
+// Compression returns the compression detected on the input
+func (r *Reader) Compression() (Compression, error) {
+	if err := r.start(); err != nil {
+		return NoCompression, err
+	}
+	return r.compression, nil
+}
+
+// Line returns the 0-based index of the line most recently returned by ReadLine
+func (r *Reader) Line() int {
+	return r.line
+}
+
+// Offset returns the byte offset of the line most recently returned by ReadLine
+func (r *Reader) Offset() int64 {
+	return r.offset
+}
+
+func (r *Reader) start() error {
+	if r.started {
+		return nil
+	}
+	d, c, err := Decompress(r.r)
+	if err != nil {
+		r.done = true
+		return err
+	}
+	if c != NoCompression {
+		r.r = bufio.NewReader(d)
+		r.compression = c
+	}
+	b, err := r.r.Peek(4)
+	if err != nil && err != io.EOF {
+		return err
+	}
+	var n int
+	r.enc, n = detectBom(b)
+	r.bom = n > 0
+	r.started = true
+	if n == 0 && r.Strict {
+		r.done = true
+		err := ErrMissingBom
+		if isPartialBom(b) {
+			err = ErrInvalidBom
+		}
+		return &DecodeError{Err: err}
+	}
+	r.r.Discard(n)
+	r.pos = int64(n)
+	return nil
+}
+
+// ReadLine returns the next line without its line feed. A document always has
+// at least one line; io.EOF is returned after the last one.
+func (r *Reader) ReadLine() (string, error) {
+	b, err := r.ReadLineBytes()
+	if err != nil {
+		return "", err
+	}
+	return string(b), nil
+}
+
+// ReadLineBytes is like ReadLine but returns the line as UTF-8 bytes. The slice
+// is only valid until the next call.
+func (r *Reader) ReadLineBytes() ([]byte, error) {
+	if len(r.pending) > 0 {
+		p := r.pending[0]
+		r.pending = r.pending[1:]
+		r.line++
+		r.offset = p.offset
+		return p.b, nil
+	}
+	if r.done {
+		return nil, io.EOF
+	}
+	if err := r.start(); err != nil {
+		return nil, err
+	}
+	r.line++
+	r.offset = r.pos
+
+	eol, err := r.readRaw()
+	if err == nil && r.MaxLineLength > 0 && len(r.raw) > r.MaxLineLength {
+		err = r.errorAt(r.MaxLineLength, ErrLineTooLong)
+	}
+	if err != nil {
+		r.done = true
+		return nil, err
+	}
+	if !eol {
+		r.done = true
+	}
+	b, err := r.decode()
+	if err != nil {
+		return nil, err
+	}
+	return r.applyLineEndings(b, eol)
+}
+
+// readRaw reads the encoded bytes of the next line into r.raw, without the line
+// feed, and reports whether a line feed ended the line
+func (r *Reader) readRaw() (bool, error) {
+	r.raw = r.raw[:0]
+	size := unitSize(r.enc)
+	for {
+		chunk, err := r.r.ReadSlice('\n')
+		r.raw = append(r.raw, chunk...)
+		r.pos += int64(len(chunk))
+		if r.MaxLineLength > 0 && len(r.raw) > r.MaxLineLength+size {
+			return false, r.errorAt(r.MaxLineLength, ErrLineTooLong)
+		}
+		if err == bufio.ErrBufferFull {
+			continue
+		} else if err == io.EOF {
+			if rem := len(r.raw) % size; rem != 0 {
+				return false, r.errorAt(len(r.raw)-rem, ErrTruncated)
+			}
+			return false, nil
+		} else if err != nil {
+			return false, r.errorAt(len(r.raw), err)
+		}
+
+		n := len(r.raw)
+		if r.enc == Utf16Reverse {
+			// the line feed is 0A 00, so the byte after it has to be checked
+			if (n-1)%2 != 0 {
+				continue
+			}
+			next, err := r.r.ReadByte()
+			if err == io.EOF {
+				return false, r.errorAt(n-1, ErrTruncated)
+			} else if err != nil {
+				return false, r.errorAt(n, err)
+			}
+			r.raw = append(r.raw, next)
+			r.pos++
+			if next == 0x00 {
+				r.raw = r.raw[:n-1]
+				return true, nil
+			}
+		} else if n%size == 0 && isZero(r.raw[n-size:n-1]) {
+			r.raw = r.raw[:n-size]
+			return true, nil
+		}
+	}
+}
+
+func isZero(b []byte) bool {
+	for _, c := range b {
+		if c != 0 {
+			return false
+		}
+	}
+	return true
+}
+
+func unitSize(enc ReliableTxtEncoding) int {
+	switch enc {
+	case Utf16, Utf16Reverse:
+		return 2
+	case Utf32:
+		return 4
+	default:
+		return 1
+	}
+}
+
+// errorAt returns a DecodeError for the byte at index i of the raw line
+func (r *Reader) errorAt(i int, err error) error {
+	return &DecodeError{
+		Line:   r.line,
+		Column: r.column(i),
+		Offset: r.offset + int64(i),
+		Err:    err,
+	}
+}
+
+// column returns the number of code points in the raw line before byte index i
+func (r *Reader) column(i int) int {
+	switch r.enc {
+	case Utf16, Utf16Reverse:
+		col := 0
+		for j := 0; j+1 < i; j += 2 {
+			if u := r.unit16(j); !utf16.IsSurrogate(rune(u)) || u >= 0xdc00 {
+				col++
+			}
+		}
+		return col
+	case Utf32:
+		return i / 4
+	default:
+		return utf8.RuneCount(r.raw[:i])
+	}
+}
+
+func (r *Reader) unit16(i int) uint16 {
+	if r.enc == Utf16Reverse {
+		return uint16(r.raw[i]) | uint16(r.raw[i+1])<<8
+	}
+	return uint16(r.raw[i])<<8 | uint16(r.raw[i+1])
+}
+
+// decode converts r.raw to UTF-8 in r.buf
+func (r *Reader) decode() ([]byte, error) {
+	r.buf = r.buf[:0]
+	switch r.enc {
+	case Utf16, Utf16Reverse:
+		for i := 0; i < len(r.raw); i += 2 {
+			u := rune(r.unit16(i))
+			if utf16.IsSurrogate(u) {
+				if u < 0xdc00 && i+3 < len(r.raw) {
+					if c := utf16.DecodeRune(u, rune(r.unit16(i+2))); c != utf8.RuneError {
+						r.buf = utf8.AppendRune(r.buf, c)
+						i += 2
+						continue
+					}
+				}
+				if r.Strict {
+					return nil, r.errorAt(i, ErrLoneSurrogate)
+				}
+			} else if u == 0xfeff && r.Strict {
+				return nil, r.errorAt(i, ErrStrayBom)
+			}
+			r.buf = utf8.AppendRune(r.buf, u)
+		}
+	case Utf32:
+		for i := 0; i < len(r.raw); i += 4 {
+			u := rune(r.raw[i])<<24 | rune(r.raw[i+1])<<16 | rune(r.raw[i+2])<<8 | rune(r.raw[i+3])
+			if r.Strict {
+				if utf16.IsSurrogate(u) {
+					return nil, r.errorAt(i, ErrLoneSurrogate)
+				} else if !utf8.ValidRune(u) {
+					return nil, r.errorAt(i, ErrInvalidSequence)
+				} else if u == 0xfeff {
+					return nil, r.errorAt(i, ErrStrayBom)
+				}
+			}
+			r.buf = utf8.AppendRune(r.buf, u)
+		}
+	default:
+		if utf8.Valid(r.raw) && !(r.Strict && bytes.Contains(r.raw, bomUtf8)) {
+			return r.raw, nil
+		}
+		for i := 0; i < len(r.raw); {
+			c, n := utf8.DecodeRune(r.raw[i:])
+			if r.Strict {
+				if c == utf8.RuneError && n == 1 {
+					return nil, r.errorAt(i, ErrInvalidSequence)
+				} else if c == 0xfeff {
+					return nil, r.errorAt(i, ErrStrayBom)
+				}
+			}
+			r.buf = utf8.AppendRune(r.buf, c)
+			i += n
+		}
+	}
+	return r.buf, nil
+}
+
+// Validate reads all of r in strict mode and returns the first problem found
+func Validate(r io.Reader) error {
+	rd := NewReader(r)
+	rd.Strict = true
+	for {
+		if _, err := rd.ReadLineBytes(); err == io.EOF {
+			return nil
+		} else if err != nil {
+			return err
+		}
+	}
+}
@@ -0,0 +1,170 @@
+package rtxt
+
+import (
+	"bytes"
+	"encoding/hex"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+)
+
+func TestReader(t *testing.T) {
+	type test struct {
+		hex     string
+		lines   []string
+		offsets []int64
+	}
+	tests := []test{
+		{"", []string{""}, []int64{0}},
+		{"efbbbf", []string{""}, []int64{3}},
+		{"efbbbf0a", []string{"", ""}, []int64{3, 4}},
+		{"efbbbf610a62", []string{"a", "b"}, []int64{3, 5}},
+		{"efbbbf610a620a", []string{"a", "b", ""}, []int64{3, 5, 7}},
+		{"efbbbf61ff0a62", []string{"a�", "b"}, []int64{3, 6}},
+		{"feff0061000a0a0a", []string{"a", "ਊ"}, []int64{2, 6}},
+		{"feffd834dd1e000a0062", []string{"𝄞", "b"}, []int64{2, 8}},
+		{"feffd834000a", []string{"�", ""}, []int64{2, 6}},
+		{"fffe61000a000a0a", []string{"a", "ਊ"}, []int64{2, 6}},
+		{"fffe000a0a00", []string{"਀", ""}, []int64{2, 6}},
+		{"0000feff000000610000000a0001d11e", []string{"a", "𝄞"}, []int64{4, 12}},
+		{"0000feff00000a0a", []string{"ਊ"}, []int64{4}},
+	}
+	for i, test := range tests {
+		b, _ := hex.DecodeString(test.hex)
+		r := NewReader(bytes.NewReader(b))
+		for j, expected := range test.lines {
+			line, err := r.ReadLine()
+			if err != nil {
+				t.Errorf("%v: line %v: unexpected error: %v", i, j, err)
+				break
+			}
+			if line != expected {
+				t.Errorf("%v: line %v: expected %q, received %q", i, j, expected, line)
+			}
+			if r.Line() != j {
+				t.Errorf("%v: line %v: expected line index %v, received %v", i, j, j, r.Line())
+			}
+			if r.Offset() != test.offsets[j] {
+				t.Errorf("%v: line %v: expected offset %v, received %v", i, j, test.offsets[j], r.Offset())
+			}
+		}
+		if _, err := r.ReadLine(); err != io.EOF {
+			t.Errorf("%v: expected EOF, received %v", i, err)
+		}
+	}
+}
+
+func TestReaderTruncated(t *testing.T) {
+	type test struct {
+		hex    string
+		line   int
+		column int
+		offset int64
+	}
+	tests := []test{
+		{"feff0061000a006100", 1, 1, 8},
+		{"fffe61000a", 0, 1, 4},
+		{"0000feff0000006100", 0, 1, 8},
+	}
+	for i, test := range tests {
+		b, _ := hex.DecodeString(test.hex)
+		_, err := ReadLines(bytes.NewReader(b))
+		var de *DecodeError
+		if !errors.As(err, &de) || !errors.Is(err, ErrTruncated) {
+			t.Errorf("%v: expected truncated error, received %v", i, err)
+			continue
+		}
+		if de.Line != test.line || de.Column != test.column || de.Offset != test.offset {
+			t.Errorf("%v: expected %v:%v@%v, received %v", i, test.line, test.column, test.offset, de)
+		}
+	}
+}
+
+func TestReaderLongLine(t *testing.T) {
+	long := strings.Repeat("abcdefgh", 100000)
+	for _, enc := range []ReliableTxtEncoding{Utf8, Utf16, Utf16Reverse, Utf32} {
+		var buf bytes.Buffer
+		WriteLines(&buf, []string{"a", long, "b"}, enc)
+		lines, err := ReadLines(&buf)
+		if err != nil {
+			t.Errorf("%v: unexpected error: %v", enc, err)
+		}
+		if len(lines) != 3 || lines[1] != long {
+			t.Errorf("%v: long line was not read", enc)
+		}
+	}
+}
+
+func TestReaderStrict(t *testing.T) {
+	type test struct {
+		hex    string
+		err    error
+		line   int
+		column int
+		offset int64
+	}
+	tests := []test{
+		{"efbbbf610a62", nil, 0, 0, 0},
+		{"efbbbfefbfbd", nil, 0, 0, 0},
+		{"feffd834dd1e", nil, 0, 0, 0},
+		{"6162", ErrMissingBom, 0, 0, 0},
+		{"efbb62", ErrInvalidBom, 0, 0, 0},
+		{"efbbbf610a62ff63", ErrInvalidSequence, 1, 1, 6},
+		{"efbbbf610ac3", ErrInvalidSequence, 1, 0, 5},
+		{"efbbbf61efbbbf", ErrStrayBom, 0, 1, 4},
+		{"feff0061d834", ErrLoneSurrogate, 0, 1, 4},
+		{"feff0061dd1e0062", ErrLoneSurrogate, 0, 1, 4},
+		{"feff000a0061d8340062", ErrLoneSurrogate, 1, 1, 6},
+		{"fffe610034d8", ErrLoneSurrogate, 0, 1, 4},
+		{"fffe6100fffe", ErrStrayBom, 0, 1, 4},
+		{"0000feff0000d834", ErrLoneSurrogate, 0, 0, 4},
+		{"0000feff0000006100110000", ErrInvalidSequence, 0, 1, 8},
+		{"0000feff0000feff", ErrStrayBom, 0, 0, 4},
+	}
+	for i, test := range tests {
+		b, _ := hex.DecodeString(test.hex)
+		err := Validate(bytes.NewReader(b))
+		if test.err == nil {
+			if err != nil {
+				t.Errorf("%v: unexpected error: %v", i, err)
+			}
+			continue
+		}
+		var de *DecodeError
+		if !errors.As(err, &de) || !errors.Is(err, test.err) {
+			t.Errorf("%v: expected %v, received %v", i, test.err, err)
+			continue
+		}
+		if de.Line != test.line || de.Column != test.column || de.Offset != test.offset {
+			t.Errorf("%v: expected %v:%v@%v, received %v", i, test.line, test.column, test.offset, de)
+		}
+
+		// without Strict the same input is read with replacements
+		if test.err != ErrMissingBom && test.err != ErrInvalidBom {
+			if _, err := ReadLines(bytes.NewReader(b)); err != nil {
+				t.Errorf("%v: unexpected error in lenient mode: %v", i, err)
+			}
+		}
+	}
+}
+
+func TestReaderMaxLineLength(t *testing.T) {
+	for _, enc := range []ReliableTxtEncoding{Utf8, Utf16, Utf16Reverse, Utf32} {
+		size := unitSize(enc)
+		for _, n := range []int{3, 5000} {
+			var buf bytes.Buffer
+			WriteLines(&buf, []string{strings.Repeat("a", n), strings.Repeat("b", n+1)}, enc)
+			r := NewReader(&buf)
+			r.MaxLineLength = n * size
+			if line, err := r.ReadLine(); err != nil || len(line) != n {
+				t.Errorf("%v: expected line of %v, received %v %v", enc, n, len(line), err)
+			}
+			_, err := r.ReadLine()
+			var de *DecodeError
+			if !errors.As(err, &de) || !errors.Is(err, ErrLineTooLong) || de.Line != 1 || de.Column != n {
+				t.Errorf("%v: expected line too long error, received %v", enc, err)
+			}
+		}
+	}
+}
@@ -0,0 +1,110 @@
+package rtxt
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+)
+
+// Writer writes lines of a ReliableTXT document. The byte order mark is written
+// before the first line and lines are separated by a single line feed.
+type Writer struct {
+	// NoBom leaves out the byte order mark of a UTF-8 document; it must be set
+	// before the first line is written
+	NoBom bool
+
+	w       *bufio.Writer
+	cw      *countWriter
+	enc     ReliableTxtEncoding
+	buf     []byte
+	started bool
+	lines   int
+	closed  bool
+	err     error
+}
+
+func NewWriter(w io.Writer, enc ReliableTxtEncoding) *Writer {
+	cw := &countWriter{w: w}
+	return &Writer{
+		w:   bufio.NewWriter(cw),
+		cw:  cw,
+		enc: enc,
+	}
+}
+
+// countWriter counts the bytes accepted by w
+type countWriter struct {
+	w io.Writer
+	n int64
+}
+
+func (c *countWriter) Write(p []byte) (int, error) {
+	n, err := c.w.Write(p)
+	c.n += int64(n)
+	return n, err
+}
+
+// WriteLine encodes and writes a line and returns the number of encoded bytes,
+// including the byte order mark or line feed that precedes it.
+func (w *Writer) WriteLine(s string) (int, error) {
+	if w.err != nil {
+		return 0, w.err
+	}
+	if w.closed {
+		return 0, fmt.Errorf("Write to closed writer")
+	}
+	w.buf = w.buf[:0]
+	if !w.started {
+		if !w.NoBom || w.enc != Utf8 {
+			w.buf = append(w.buf, bom(w.enc)...)
+		}
+		w.started = true
+	} else if w.lines > 0 {
+		w.buf = appendRune(w.buf, '\n', w.enc)
+	}
+	w.buf = appendEncoded(w.buf, s, w.enc)
+	n, err := w.w.Write(w.buf)
+	w.lines++
+	if err != nil {
+		w.err = err
+	}
+	return n, err
+}
+
+// Written returns the number of encoded bytes the underlying writer has
+// accepted; buffered bytes are counted once they are flushed
+func (w *Writer) Written() int64 {
+	return w.cw.n
+}
+
+// Lines returns the number of lines written so far
+func (w *Writer) Lines() int {
+	return w.lines
+}
+
+func (w *Writer) Error() error {
+	return w.err
+}
+
+func (w *Writer) Flush() error {
+	if w.err != nil {
+		return w.err
+	}
+	if err := w.w.Flush(); err != nil {
+		w.err = err
+	}
+	return w.err
+}
+
+// Close writes the byte order mark if no line was written, since even an empty
+// document has one, and flushes. It does not close the underlying writer.
+func (w *Writer) Close() error {
+	if w.closed {
+		return w.err
+	}
+	if !w.started {
+		w.WriteLine("")
+	}
+	w.closed = true
+	return w.Flush()
+}
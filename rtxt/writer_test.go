@@ -0,0 +1,105 @@
+package rtxt
+
+import (
+	"bytes"
+	"encoding/hex"
+	"errors"
+	"testing"
+)
+
+func TestWriteLines(t *testing.T) {
+	type test struct {
+		lines []string
+		enc   ReliableTxtEncoding
+		hex   string
+	}
+	tests := []test{
+		{nil, Utf8, "efbbbf"},
+		{[]string{""}, Utf8, "efbbbf"},
+		{[]string{"", ""}, Utf8, "efbbbf0a"},
+		{[]string{"a", "b"}, Utf8, "efbbbf610a62"},
+		{[]string{"a", "𝄞"}, Utf16, "feff0061000ad834dd1e"},
+		{[]string{"a", "𝄞"}, Utf16Reverse, "fffe61000a0034d81edd"},
+		{[]string{"a", "𝄞"}, Utf32, "0000feff000000610000000a0001d11e"},
+		{[]string{"\uFEFF"}, Utf8, "efbbbfefbbbf"},
+		{[]string{"a\xffb"}, Utf8, "efbbbf61efbfbd62"},
+	}
+	for i, test := range tests {
+		var buf bytes.Buffer
+		expected, _ := hex.DecodeString(test.hex)
+		n, err := WriteLines(&buf, test.lines, test.enc)
+		if err != nil {
+			t.Errorf("%v: unexpected error: %v", i, err)
+		}
+		if !bytes.Equal(expected, buf.Bytes()) {
+			t.Errorf("%v: expected %x, received %x", i, expected, buf.Bytes())
+		}
+		if n != len(expected) {
+			t.Errorf("%v: expected %v bytes, received %v", i, len(expected), n)
+		}
+	}
+}
+
+func TestWriterNoBom(t *testing.T) {
+	for _, enc := range []ReliableTxtEncoding{Utf8, Utf16} {
+		var buf bytes.Buffer
+		w := NewWriter(&buf, enc)
+		w.NoBom = true
+		w.WriteLine("a")
+		w.Close()
+		r := NewReader(&buf)
+		if bom, err := r.HasBom(); err != nil || bom != (enc != Utf8) {
+			t.Errorf("%v: expected byte order mark %v, received %v %v", enc, enc != Utf8, bom, err)
+		}
+		if line, err := r.ReadLine(); err != nil || line != "a" {
+			t.Errorf("%v: expected %q, received %q %v", enc, "a", line, err)
+		}
+	}
+}
+
+type failingWriter struct {
+	n   int
+	err error
+}
+
+func (w *failingWriter) Write(p []byte) (int, error) {
+	if len(p) > w.n {
+		n := w.n
+		w.n = 0
+		return n, w.err
+	}
+	w.n -= len(p)
+	return len(p), nil
+}
+
+func TestWriterError(t *testing.T) {
+	failure := errors.New("failure")
+	w := NewWriter(&failingWriter{n: 2, err: failure}, Utf8)
+	if n, err := w.WriteLine("abc"); n != 6 || err != nil {
+		t.Errorf("expected buffered write of 6 bytes, received %v %v", n, err)
+	}
+	if err := w.Flush(); err != failure {
+		t.Errorf("expected %v, received %v", failure, err)
+	}
+	if _, err := w.WriteLine("d"); err != failure {
+		t.Errorf("expected sticky %v, received %v", failure, err)
+	}
+	if err := w.Close(); !errors.Is(err, failure) {
+		t.Errorf("expected %v, received %v", failure, err)
+	}
+
+	if w.Written() != 2 {
+		t.Errorf("expected 2 bytes accepted, received %v", w.Written())
+	}
+	if n, err := WriteLines(&failingWriter{n: 2, err: failure}, []string{"abc", "def"}, Utf8); n != 2 || err != failure {
+		t.Errorf("expected 2 bytes and %v, received %v %v", failure, n, err)
+	}
+
+	big := make([]string, 10000)
+	for i := range big {
+		big[i] = "line"
+	}
+	if _, err := WriteLines(&failingWriter{n: 100, err: failure}, big, Utf8); err != failure {
+		t.Errorf("expected %v, received %v", failure, err)
+	}
+}
@@ -0,0 +1,84 @@
+package sml
+
+import (
+	"context"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/wjanssens/rtxt"
+)
+
+// Document is an SML document with the encoding and compression it is saved in
+type Document struct {
+	Root        *Node
+	Encoding    rtxt.ReliableTxtEncoding
+	Compression rtxt.Compression
+	// NoBom is set for a UTF-8 document read without a byte order mark, which
+	// is then written without one too
+	NoBom bool
+}
+
+func NewDocument() *Document {
+	root := NewRoot()
+	return &Document{
+		Root:     &root,
+		Encoding: rtxt.Utf8,
+	}
+}
+
+func ReadDocument(r io.Reader, preserveWhitespaceAndComments bool) (*Document, error) {
+	d := NewDocument()
+	rd := rtxt.NewReader(r)
+	root, err := parse(context.Background(), rd, preserveWhitespaceAndComments, 0, nil)
+	d.Root = root
+	if err != nil {
+		return d, err
+	}
+	d.Encoding, _ = rd.Encoding()
+	d.Compression, _ = rd.Compression()
+	bom, _ := rd.HasBom()
+	d.NoBom = !bom
+	return d, nil
+}
+
+func LoadDocument(path string, preserveWhitespaceAndComments bool) (*Document, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return ReadDocument(f, preserveWhitespaceAndComments)
+}
+
+func (d *Document) String() string {
+	return strings.Join(d.Root.lines(make([]string, 0)), "\n")
+}
+
+// WriteTo writes the document in its encoding, including the byte order mark
+// unless NoBom is set
+func (d *Document) WriteTo(w io.Writer) (int64, error) {
+	e := rtxt.NewWriter(w, d.Encoding)
+	e.NoBom = d.NoBom
+	for _, l := range d.Root.lines(make([]string, 0)) {
+		if _, err := e.WriteLine(l); err != nil {
+			return e.Written(), err
+		}
+	}
+	err := e.Close()
+	return e.Written(), err
+}
+
+func (d *Document) Save(path string) error {
+	return d.SaveWithOptions(path, nil)
+}
+
+// SaveWithOptions saves the document with rtxt.WriteFile, which replaces the
+// file atomically and optionally keeps a backup; it is compressed with
+// d.Compression unless opts sets a compression
+func (d *Document) SaveWithOptions(path string, opts *rtxt.SaveOptions) error {
+	return rtxt.WriteFile(path, opts.WithCompression(d.Compression), func(w io.Writer) error {
+		_, err := d.WriteTo(w)
+		return err
+	})
+}
@@ -0,0 +1,94 @@
+package sml
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/wjanssens/rtxt"
+)
+
+func TestDocumentSave(t *testing.T) {
+	const doc = "Root\n  Name \"a b\"\nEnd"
+	var buf bytes.Buffer
+	rtxt.WriteLines(&buf, rtxt.Split(doc), rtxt.Utf16)
+
+	d, err := ReadDocument(&buf, true)
+	if err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	if d.Encoding != rtxt.Utf16 || d.String() != doc {
+		t.Fatalf("expected %v %q, got %v %q", rtxt.Utf16, doc, d.Encoding, d.String())
+	}
+
+	path := filepath.Join(t.TempDir(), "doc.sml")
+	os.WriteFile(path, []byte("old"), 0644)
+	if err := d.SaveWithOptions(path, &rtxt.SaveOptions{Backup: true}); err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	if b, _ := os.ReadFile(path + ".bak"); string(b) != "old" {
+		t.Errorf("expected backup of previous file, got %q", b)
+	}
+	saved, err := LoadDocument(path, true)
+	if err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	if saved.Encoding != rtxt.Utf16 || saved.String() != doc {
+		t.Errorf("expected %v %q, got %v %q", rtxt.Utf16, doc, saved.Encoding, saved.String())
+	}
+}
+
+func TestReadDocumentBom(t *testing.T) {
+	d, err := ReadDocument(bytes.NewReader([]byte("\xEF\xBB\xBF\xEF\xBB\xBFRoot\nEnd")), true)
+	if err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	if expected := "\uFEFFRoot\nEnd"; d.String() != expected || d.Encoding != rtxt.Utf8 {
+		t.Errorf("expected %q, got %v %q", expected, d.Encoding, d.String())
+	}
+}
+
+func TestDocumentNoBom(t *testing.T) {
+	const doc = "Root\n  Name a\nEnd"
+	d, err := ReadDocument(bytes.NewReader([]byte(doc)), true)
+	if err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	var out bytes.Buffer
+	if _, err := d.WriteTo(&out); err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	if !d.NoBom || out.String() != doc {
+		t.Errorf("expected %q without a byte order mark, got %q", doc, out.String())
+	}
+}
+
+func TestDocumentCompression(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "doc.sml.gz")
+	d := NewDocument()
+	e, _ := d.Root.AddElement("Root")
+	e.AddAttribute("Name", []string{"x"})
+	gz := rtxt.Gzip
+	if err := d.SaveWithOptions(path, &rtxt.SaveOptions{Compression: &gz}); err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	if b, _ := os.ReadFile(path); !bytes.HasPrefix(b, []byte{0x1f, 0x8b}) {
+		t.Fatalf("expected gzip file, got %x", b)
+	}
+
+	l, err := LoadDocument(path, true)
+	if err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	if l.Compression != rtxt.Gzip || l.String() != d.String() {
+		t.Errorf("expected compressed %q, got %v %q", d.String(), l.Compression, l.String())
+	}
+	l.Root.children[0].children[0].start.SetValue(1, "y")
+	if err := l.Save(path); err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	if s, err := LoadDocument(path, true); err != nil || s.Compression != rtxt.Gzip || s.String() != l.String() {
+		t.Errorf("expected compression to be kept, got %v %q %v", s.Compression, s.String(), err)
+	}
+}
@@ -0,0 +1,40 @@
+package sml
+
+import (
+	"io/fs"
+
+	"github.com/wjanssens/rtxt"
+)
+
+func LoadFS(fsys fs.FS, name string, preserveWhitespaceAndComments bool) (*Node, error) {
+	f, err := fsys.Open(name)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return Parse(f, preserveWhitespaceAndComments, 0)
+}
+
+// LoadGlobFS parses every file in fsys matching pattern, for example
+// "conf/*.sml", and returns the root nodes by file name
+func LoadGlobFS(fsys fs.FS, pattern string, preserveWhitespaceAndComments bool) (map[string]*Node, error) {
+	return rtxt.LoadGlobFS(fsys, pattern, func(fsys fs.FS, name string) (*Node, error) {
+		return LoadFS(fsys, name, preserveWhitespaceAndComments)
+	})
+}
+
+func LoadDocumentFS(fsys fs.FS, name string, preserveWhitespaceAndComments bool) (*Document, error) {
+	f, err := fsys.Open(name)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return ReadDocument(f, preserveWhitespaceAndComments)
+}
+
+// LoadDocumentGlobFS loads every file matching pattern with LoadDocumentFS
+func LoadDocumentGlobFS(fsys fs.FS, pattern string, preserveWhitespaceAndComments bool) (map[string]*Document, error) {
+	return rtxt.LoadGlobFS(fsys, pattern, func(fsys fs.FS, name string) (*Document, error) {
+		return LoadDocumentFS(fsys, name, preserveWhitespaceAndComments)
+	})
+}
@@ -0,0 +1,49 @@
+package sml
+
+import (
+	"testing"
+	"testing/fstest"
+
+	"github.com/wjanssens/rtxt"
+)
+
+func TestLoadFS(t *testing.T) {
+	fsys := fstest.MapFS{
+		"conf/a.sml":   {Data: []byte("A\n  Name x\nEnd")},
+		"conf/b.sml":   {Data: []byte("\xfe\xff\x00B\x00\n\x00E\x00n\x00d")},
+		"conf/bad.smx": {Data: []byte("C")},
+	}
+	root, err := LoadFS(fsys, "conf/a.sml", true)
+	if err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	if elements, _ := root.FilterElements("a"); len(elements) != 1 {
+		t.Errorf("expected element A")
+	}
+
+	files, err := LoadGlobFS(fsys, "conf/*.sml", false)
+	if err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	if len(files) != 2 {
+		t.Errorf("expected 2 files, got %v", len(files))
+	}
+	if elements, _ := files["conf/b.sml"].FilterElements("b"); len(elements) != 1 {
+		t.Errorf("expected element B")
+	}
+
+	if _, err := LoadGlobFS(fsys, "conf/*.smx", false); err == nil {
+		t.Errorf("expected parse error")
+	}
+
+	docs, err := LoadDocumentGlobFS(fsys, "conf/*.sml", true)
+	if err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	if d := docs["conf/b.sml"]; len(docs) != 2 || d.Encoding != rtxt.Utf16 || d.String() != "B\nEnd" {
+		t.Errorf("unexpected documents %v", docs)
+	}
+	if _, err := LoadDocumentGlobFS(fsys, "conf/*.smx", false); err == nil {
+		t.Errorf("expected parse error")
+	}
+}
@@ -0,0 +1,46 @@
+package sml
+
+import (
+	"strings"
+	"testing"
+	"unicode/utf8"
+)
+
+func FuzzParse(f *testing.F) {
+	f.Add("Root\n  Name Value\nEnd")
+	f.Add("a\nb\n-\nend\nEND")
+	f.Add("A\n\n  # c\n  B \"x\"/\"y\" -\nEnd #e")
+	f.Fuzz(func(t *testing.T, s string) {
+		for _, preserve := range []bool{true, false} {
+			root, err := Parse(strings.NewReader(s), preserve, 0)
+			if err != nil {
+				continue
+			}
+			walk(root, func(n *Node) { n.GetName() })
+			var b strings.Builder
+			if err := root.Write(&b); err != nil {
+				t.Fatalf("unexpected error %v", err)
+			}
+			// a preserved document is serialized from its lines back to the input
+			if preserve && utf8.ValidString(s) && !strings.HasPrefix(s, "\uFEFF") && b.String() != s {
+				t.Fatalf("expected %q, got %q", s, b.String())
+			}
+			again, err := Parse(strings.NewReader(b.String()), preserve, 0)
+			if err != nil {
+				t.Fatalf("%q: unexpected error %v", b.String(), err)
+			}
+			var c strings.Builder
+			again.Write(&c)
+			if c.String() != b.String() {
+				t.Fatalf("expected %q, got %q", b.String(), c.String())
+			}
+		}
+	})
+}
+
+func walk(n *Node, fn func(n *Node)) {
+	fn(n)
+	for i := range n.children {
+		walk(&n.children[i], fn)
+	}
+}
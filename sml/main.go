@@ -4,7 +4,6 @@ import (
 	"fmt"
 	"io"
 	"strings"
-	"unicode/utf8"
 
 	"github.com/wjanssens/wsv"
 )
@@ -57,8 +56,14 @@ func (n *Node) FilterElements(name string) ([]Node, error) {
 		return nil, fmt.Errorf("Not an element")
 	}
 }
+
+// GetName returns the name of an element or attribute, or "" for the root and
+// empty nodes
 func (n *Node) GetName() string {
-	return n.start.GetValues()[0]
+	if n.start == nil || !n.start.HasValues() {
+		return ""
+	}
+	return n.start.GetValue(0)
 }
 func (n *Node) SetName(name string) {
 	n.start.SetValue(0, name)
@@ -81,13 +86,15 @@ func (n *Node) UnsetNil(i int) {
 	n.start.UnsetNil(i + 1)
 }
 func (n *Node) GetComment() string {
-	return n.start.GetComment()
+	c, _ := n.start.GetComment()
+	return c
 }
 func (n *Node) SetComment(comment string) error {
 	return n.start.SetComment(comment)
 }
 func (n *Node) GetEndComment() string {
-	return n.end.GetComment()
+	c, _ := n.end.GetComment()
+	return c
 }
 func (n *Node) SetEndComment(comment string) error {
 	if n.IsElement() {
@@ -98,34 +105,30 @@ func (n *Node) SetEndComment(comment string) error {
 }
 func (n *Node) AddElement(name string) (*Node, error) {
 	if n.IsElement() || n.IsRoot() {
-		start, _ := wsv.NewLine([]string{name}, nil, "")
-		end, _ := wsv.NewLine([]string{"end"}, nil, "")
-		node := Node{start: &start, end: &end, children: make([]Node, 0)}
-		n.children = append(n.children, node)
-		return &node, nil
+		start, _ := wsv.NewLineBuilder().Values([]string{name}).Build()
+		end, _ := wsv.NewLineBuilder().Values([]string{"end"}).Build()
+		n.children = append(n.children, Node{start: start, end: end, children: make([]Node, 0)})
+		return &n.children[len(n.children)-1], nil
 	} else {
 		return nil, fmt.Errorf("Not an element")
 	}
 }
 func (n *Node) AddAttribute(name string, values []string) (*Node, error) {
 	if n.IsElement() || n.IsRoot() {
-		v := make([]string, len(values)+1)
+		v := make([]string, 0, len(values)+1)
 		v = append(v, name)
 		v = append(v, values...)
-		start, _ := wsv.NewLine(v, nil, "")
-		node := Node{start: &start}
-		n.children = append(n.children, node)
-		return &node, nil
+		start, _ := wsv.NewLineBuilder().Values(v).Build()
+		n.children = append(n.children, Node{start: start})
+		return &n.children[len(n.children)-1], nil
 	} else {
 		return nil, fmt.Errorf("Not an element")
 	}
 }
 func (n *Node) AddEmpty() (*Node, error) {
 	if n.IsElement() || n.IsRoot() {
-		start, _ := wsv.NewLine(nil, nil, "")
-		node := Node{start: &start}
-		n.children = append(n.children, node)
-		return &node, nil
+		n.children = append(n.children, Node{start: wsv.NewLine()})
+		return &n.children[len(n.children)-1], nil
 	} else {
 		return nil, fmt.Errorf("Not an element")
 	}
@@ -161,78 +164,39 @@ func (n *Node) Filter(fn func(n *Node) bool) []Node {
 	return result
 }
 
+// Write writes the node and its descendants as lines separated by line feeds;
+// the root node itself has no lines
 func (n *Node) Write(w io.Writer) error {
-	io.WriteString(w, n.start.String())
-	if err := n.Each(func(n *Node) error {
-		return n.Write(w)
-	}); err != nil {
-		return err
-	}
-	io.WriteString(w, n.end.String())
-
-	return nil
+	_, err := io.WriteString(w, strings.Join(n.lines(make([]string, 0)), "\n"))
+	return err
 }
 
-func (n *Node) AlignAttributes(spacesBetween string, maxColumns int, rightAligned []bool) error {
-	if err := wsv.ValidateSpace(spacesBetween, false); err != nil {
-		return err
+func (n *Node) lines(lines []string) []string {
+	if n.start != nil {
+		lines = append(lines, n.start.String())
 	}
-
-	attributes := n.Filter(func(n *Node) bool { return n.IsAttribute() })
-	spacesArray := make([][]string, 0)
-	var columns int
-	for _, a := range attributes {
-		spacesArray = append(spacesArray, make([]string, 0))
-		c := len(a.start.GetValues())
-		if c > columns {
-			columns = c
-		}
+	for i := range n.children {
+		lines = n.children[i].lines(lines)
 	}
+	if n.end != nil {
+		lines = append(lines, n.end.String())
+	}
+	return lines
+}
 
-	if maxColumns > columns {
-		columns = maxColumns
+func (n *Node) AlignAttributes(spacesBetween string, maxColumns int, rightAligned []bool) error {
+	attributes := n.Filter(func(n *Node) bool { return n.IsAttribute() })
+	lines := make([]*wsv.Line, len(attributes))
+	for i, a := range attributes {
+		lines[i] = a.start
 	}
-	for i := 0; i < columns; i++ {
-		var maxLen int
-		for j, a := range attributes {
-			values := a.start.GetValues()
-			if j >= len(values) {
-				continue
-			}
-			value := values[j]
 
-			serValue := wsv.SerializeValue(value, false)
-			cpLen := utf8.RuneCountInString(serValue)
-			if cpLen > maxLen {
-				maxLen = cpLen
-			}
-		}
-		for j, a := range attributes {
-			values := a.start.GetValues()
-			if j >= len(values) {
-				continue
-			}
-			value := values[j]
-			serValue := wsv.SerializeValue(value, false)
-			lengthDif := maxLen - utf8.RuneCountInString(serValue)
-			fillingWhitespace := strings.Repeat(" ", lengthDif)
-			if rightAligned[j] {
-				last := spacesArray[j][len(spacesArray[j])-1]
-				spacesArray[j][len(spacesArray[j])-1] = last + fillingWhitespace
-				if i >= len(values)-1 {
-					continue
-				}
-				spacesArray[j] = append(spacesArray[j], spacesBetween)
-			} else {
-				if i >= len(values)-1 {
-					continue
-				}
-				spacesArray[j] = append(spacesArray[j], fillingWhitespace+spacesBetween)
-			}
+	align := make([]wsv.Alignment, len(rightAligned))
+	for i, r := range rightAligned {
+		if r {
+			align[i] = wsv.AlignRight
 		}
 	}
-	for i, a := range attributes {
-		a.SetSpaces(spacesArray[i])
-	}
-	return nil
+	f := wsv.Formatter{Spacing: spacesBetween, Align: align, MaxColumns: maxColumns}
+	return f.Format(lines)
 }
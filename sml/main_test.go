@@ -21,3 +21,25 @@ func TestIsMethods(t *testing.T) {
 		t.Errorf("expected empty.IsEmpty() == true")
 	}
 }
+
+func TestAlignAttributes(t *testing.T) {
+	r := NewRoot()
+	r.AddAttribute("Name", []string{"1"})
+	r.AddAttribute("LongerName", []string{"100"})
+	r.AddElement("Child")
+
+	if err := r.AlignAttributes(" ", 0, []bool{false, true}); err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	var got []string
+	r.Each(func(n *Node) error {
+		if n.IsAttribute() {
+			got = append(got, n.start.String())
+		}
+		return nil
+	})
+	expected := []string{"Name         1", "LongerName 100"}
+	if len(got) != 2 || got[0] != expected[0] || got[1] != expected[1] {
+		t.Errorf("expected %q, got %q", expected, got)
+	}
+}
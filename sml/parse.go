@@ -1,6 +1,9 @@
 package sml
 
 import (
+	"context"
+	"errors"
+	"fmt"
 	"io"
 	"strings"
 
@@ -8,41 +11,63 @@ import (
 	"github.com/wjanssens/wsv"
 )
 
+var ErrTooDeep = errors.New("Elements nested too deep")
+
+// Limits adds a limit on element nesting to the wsv limits; a zero field means
+// no limit. Exceeding MaxDepth is reported as a wsv.LimitError with ErrTooDeep.
+type Limits struct {
+	wsv.Limits
+	MaxDepth int
+}
+
 func Parse(r io.Reader, preserveWhitespaceAndComments bool, lineIndexOffset int) (*Node, error) {
+	return ParseContext(context.Background(), r, preserveWhitespaceAndComments, lineIndexOffset, nil)
+}
+
+func ParseContext(ctx context.Context, r io.Reader, preserveWhitespaceAndComments bool, lineIndexOffset int, limits *Limits) (*Node, error) {
+	return parse(ctx, rtxt.NewReader(r), preserveWhitespaceAndComments, lineIndexOffset, limits)
+}
+
+// parse implements ParseContext reading from rd, which then holds the encoding
+// and compression of the parsed document
+func parse(ctx context.Context, rd *rtxt.Reader, preserveWhitespaceAndComments bool, lineIndexOffset int, limits *Limits) (*Node, error) {
+	if limits == nil {
+		limits = &Limits{}
+	}
 	root := NewRoot()
 	curr := &root
 	stk := stack{}
-	stk.Push(curr)
-
-	s := rtxt.ScanLines(r)
 
-	lineIndex := lineIndexOffset - 1
-	for s.Scan() {
-		lineIndex++
-		if lineIndex < lineIndexOffset {
-			continue
-		}
-		if line, err := wsv.ParseLine(s.Text(), preserveWhitespaceAndComments); err != nil {
-			return curr, err // TODO better to return root, or current node?
-		} else {
-			values := line.GetValues()
-			name := values[0]
-			if len(values) == 0 {
-				n := Node{start: &line}
-				curr.children = append(curr.children, n)
-			} else if len(values) == 1 {
-				if strings.EqualFold(name, "end") {
-					curr := stk.Pop()
-					curr.end = &line
-				} else {
-					n := Node{start: &line, children: make([]Node, 0)}
-					curr.children = append(curr.children, n)
+	err := wsv.ParseEachReader(ctx, rd, preserveWhitespaceAndComments, lineIndexOffset, &limits.Limits, func(lineIndex int, line *wsv.Line) error {
+		if line.Len() == 0 {
+			curr.children = append(curr.children, Node{start: line})
+		} else if line.Len() == 1 {
+			if line.IsNil(0) {
+				return fmt.Errorf("Null value as element name is not allowed")
+			} else if strings.EqualFold(line.GetValue(0), "end") {
+				if len(stk) == 0 {
+					return fmt.Errorf("Unexpected end keyword")
 				}
+				curr.end = line
+				curr = stk.Pop()
 			} else {
-				n := Node{}
-				curr.children = append(curr.children, n)
+				if limits.MaxDepth > 0 && len(stk) >= limits.MaxDepth {
+					return &wsv.LimitError{LineIndex: lineIndex, Limit: limits.MaxDepth, Err: ErrTooDeep}
+				}
+				curr.children = append(curr.children, Node{start: line, children: make([]Node, 0)})
+				stk.Push(curr)
+				curr = &curr.children[len(curr.children)-1]
 			}
+		} else {
+			curr.children = append(curr.children, Node{start: line})
 		}
+		return nil
+	})
+	if err != nil {
+		return &root, err
+	}
+	if len(stk) > 0 {
+		return &root, fmt.Errorf("Element %v not closed", curr.GetName())
 	}
 	return &root, nil
 }
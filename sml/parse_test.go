@@ -0,0 +1,80 @@
+package sml
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/wjanssens/wsv"
+)
+
+func TestParse(t *testing.T) {
+	const doc = `Root
+  Name Value1 "Value 2"
+  # comment
+  Child
+    Attribute -
+  End
+End`
+	root, err := Parse(strings.NewReader(doc), true, 0)
+	if err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	elements, _ := root.FilterElements("root")
+	if len(elements) != 1 {
+		t.Fatalf("expected 1 root element, got %v", len(elements))
+	}
+	r := elements[0]
+	if !r.IsElement() || len(r.children) != 3 {
+		t.Fatalf("expected root element with 3 children, got %v", len(r.children))
+	}
+	attributes, _ := r.FilterAttributes("name")
+	if len(attributes) != 1 || attributes[0].getAttributes()[1] != "Value 2" {
+		t.Errorf("expected attribute Name")
+	}
+	if !r.children[1].IsEmpty() {
+		t.Errorf("expected comment line to be empty node")
+	}
+	children, _ := r.FilterElements("child")
+	if len(children) != 1 || !children[0].children[0].IsNil(0) {
+		t.Errorf("expected child element with null attribute")
+	}
+}
+
+func TestParseInvalid(t *testing.T) {
+	invalid := []string{
+		"Root",
+		"End",
+		"Root\nEnd\nEnd",
+		"-\nEnd",
+		"Root \"\nEnd",
+	}
+	for i, s := range invalid {
+		if _, err := Parse(strings.NewReader(s), true, 0); err == nil {
+			t.Errorf("%v: expected %q to be invalid", i, s)
+		}
+	}
+}
+
+func TestParseContextLimits(t *testing.T) {
+	const doc = "A\nB\nC\nEnd\nEnd\nEnd"
+	if _, err := ParseContext(context.Background(), strings.NewReader(doc), true, 0, &Limits{MaxDepth: 3}); err != nil {
+		t.Errorf("unexpected error %v", err)
+	}
+	_, err := ParseContext(context.Background(), strings.NewReader(doc), true, 0, &Limits{MaxDepth: 2})
+	var le *wsv.LimitError
+	if !errors.As(err, &le) || !errors.Is(err, ErrTooDeep) || le.LineIndex != 2 {
+		t.Errorf("expected depth error at line 2, got %v", err)
+	}
+	_, err = ParseContext(context.Background(), strings.NewReader(doc), true, 0, &Limits{Limits: wsv.Limits{MaxLines: 4}})
+	if !errors.Is(err, wsv.ErrTooManyLines) {
+		t.Errorf("expected line limit error, got %v", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := ParseContext(ctx, strings.NewReader(doc), true, 0, nil); !errors.Is(err, context.Canceled) {
+		t.Errorf("expected cancellation, got %v", err)
+	}
+}
@@ -0,0 +1,74 @@
+package wsv
+
+// bitset is a growable set of small non-negative integers
+type bitset []uint64
+
+func (b bitset) has(i int) bool {
+	w := i / 64
+	return i >= 0 && w < len(b) && b[w]&(1<<(uint(i)%64)) != 0
+}
+
+func (b *bitset) set(i int) {
+	w := i / 64
+	for len(*b) <= w {
+		*b = append(*b, 0)
+	}
+	(*b)[w] |= 1 << (uint(i) % 64)
+}
+
+func (b bitset) unset(i int) {
+	if w := i / 64; i >= 0 && w < len(b) {
+		b[w] &^= 1 << (uint(i) % 64)
+	}
+}
+
+func (b *bitset) setTo(i int, v bool) {
+	if v {
+		b.set(i)
+	} else {
+		b.unset(i)
+	}
+}
+
+// insert shifts the members from i up by one, leaving i unset; there are no
+// members to shift when i is past the last word
+func (b *bitset) insert(i int) {
+	if i < 0 || i >= len(*b)*64 {
+		return
+	}
+	w := i / 64
+	if (*b)[len(*b)-1]&(1<<63) != 0 {
+		// the last member moves into a new word
+		*b = append(*b, 0)
+	}
+	for j := len(*b) - 1; j > w; j-- {
+		(*b)[j] = (*b)[j]<<1 | (*b)[j-1]>>63
+	}
+	low := uint64(1)<<(uint(i)%64) - 1
+	(*b)[w] = (*b)[w]&low | ((*b)[w]&^low)<<1
+}
+
+// remove drops i and shifts the members above it down by one
+func (b bitset) remove(i int) {
+	if i < 0 || i >= len(b)*64 {
+		return
+	}
+	w := i / 64
+	low := uint64(1)<<(uint(i)%64) - 1
+	b[w] = b[w]&low | (b[w]>>1)&^low
+	for j := w; j < len(b); j++ {
+		if j > w {
+			b[j] >>= 1
+		}
+		if j+1 < len(b) {
+			b[j] |= b[j+1] << 63
+		}
+	}
+}
+
+// truncate drops the members from n up
+func (b bitset) truncate(n int) {
+	for j := n; j < len(b)*64; j++ {
+		b.unset(j)
+	}
+}
@@ -0,0 +1,55 @@
+package wsv
+
+import (
+	"slices"
+	"testing"
+)
+
+func members(b bitset) []int {
+	m := []int{}
+	for i := 0; i < len(b)*64; i++ {
+		if b.has(i) {
+			m = append(m, i)
+		}
+	}
+	return m
+}
+
+func TestBitset(t *testing.T) {
+	tests := []struct {
+		set      []int
+		edit     func(b *bitset)
+		expected []int
+		words    int
+	}{
+		{[]int{1, 5}, func(b *bitset) { b.insert(3) }, []int{1, 6}, 1},
+		{[]int{1, 5}, func(b *bitset) { b.insert(5) }, []int{1, 6}, 1},
+		{[]int{62}, func(b *bitset) { b.insert(0) }, []int{63}, 1},
+		{[]int{63}, func(b *bitset) { b.insert(0) }, []int{64}, 2},
+		{[]int{63}, func(b *bitset) { b.insert(63) }, []int{64}, 2},
+		{[]int{63}, func(b *bitset) { b.insert(64) }, []int{63}, 1},
+		{[]int{63}, func(b *bitset) { b.insert(70) }, []int{63}, 1},
+		{[]int{3, 64}, func(b *bitset) { b.insert(64) }, []int{3, 65}, 2},
+		{[]int{3, 127}, func(b *bitset) { b.insert(4) }, []int{3, 128}, 3},
+		{[]int{1, 5}, func(b *bitset) { b.insert(-1) }, []int{1, 5}, 1},
+		{[]int{1, 5}, func(b *bitset) { b.remove(3) }, []int{1, 4}, 1},
+		{[]int{1, 5}, func(b *bitset) { b.remove(5) }, []int{1}, 1},
+		{[]int{63}, func(b *bitset) { b.remove(63) }, []int{}, 1},
+		{[]int{63, 64}, func(b *bitset) { b.remove(0) }, []int{62, 63}, 2},
+		{[]int{63, 64}, func(b *bitset) { b.remove(63) }, []int{63}, 2},
+		{[]int{63, 64}, func(b *bitset) { b.remove(64) }, []int{63}, 2},
+		{[]int{63}, func(b *bitset) { b.remove(80) }, []int{63}, 1},
+		{[]int{63}, func(b *bitset) { b.remove(-1) }, []int{63}, 1},
+		{[]int{63, 64, 130}, func(b *bitset) { b.truncate(64) }, []int{63}, 3},
+	}
+	for i, test := range tests {
+		var b bitset
+		for _, m := range test.set {
+			b.set(m)
+		}
+		test.edit(&b)
+		if m := members(b); !slices.Equal(m, test.expected) || len(b) != test.words {
+			t.Errorf("%v: expected %v in %v words, got %v in %v", i, test.expected, test.words, m, len(b))
+		}
+	}
+}
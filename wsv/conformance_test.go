@@ -0,0 +1,193 @@
+package wsv
+
+import (
+	"slices"
+	"testing"
+)
+
+// conformance is a corpus of lines following the WSV grammar. Spaces and
+// comment are those of a line parsed with whitespace and comments preserved;
+// nulls lists the indexes of null values. Invalid lines give the error kind and
+// the code point column it is reported at.
+var conformance = []struct {
+	input   string
+	values  []string
+	nulls   []int
+	spaces  []string
+	hash    bool
+	comment string
+	err     ErrorKind
+	column  int
+}{
+	// whitespace and comments
+	{input: ""},
+	{input: " \t", spaces: []string{" \t"}},
+	{input: " 　a ", values: []string{"a"}, spaces: []string{" 　", " "}},
+	{input: "#", hash: true},
+	{input: "# c ", hash: true, comment: " c "},
+	{input: "a#c", values: []string{"a"}, spaces: []string{""}, hash: true, comment: "c"},
+	{input: " a #c", values: []string{"a"}, spaces: []string{" ", " "}, hash: true, comment: "c"},
+	{input: "a ##\"", values: []string{"a"}, spaces: []string{"", " "}, hash: true, comment: "#\""},
+
+	// unquoted values
+	{input: "a b", values: []string{"a", "b"}, spaces: []string{"", " "}},
+	{input: "a\tb c", values: []string{"a", "b", "c"}, spaces: []string{"", "\t", " "}},
+	{input: "ä 𝄞 東京", values: []string{"ä", "𝄞", "東京"}, spaces: []string{"", " ", " "}},
+	{input: "a-b -a a- --", values: []string{"a-b", "-a", "a-", "--"}, spaces: []string{"", " ", " ", " "}},
+
+	// nulls
+	{input: "-", values: []string{""}, nulls: []int{0}, spaces: []string{""}},
+	{input: "-a a-b -", values: []string{"-a", "a-b", ""}, nulls: []int{2}, spaces: []string{"", " ", " "}},
+	{input: "a - b -", values: []string{"a", "", "b", ""}, nulls: []int{1, 3}, spaces: []string{"", " ", " ", " "}},
+	{input: "-#", values: []string{""}, nulls: []int{0}, spaces: []string{""}, hash: true},
+	{input: `"-"`, values: []string{"-"}, spaces: []string{""}},
+
+	// quoted values
+	{input: `""`, values: []string{""}, spaces: []string{""}},
+	{input: `"" ""`, values: []string{"", ""}, spaces: []string{"", " "}},
+	{input: `"a b" "#" " "`, values: []string{"a b", "#", " "}, spaces: []string{"", " ", " "}},
+	{input: `"a""b"`, values: []string{`a"b`}, spaces: []string{""}},
+	{input: `""""`, values: []string{`"`}, spaces: []string{""}},
+	{input: `"a"/"b"`, values: []string{"a\nb"}, spaces: []string{""}},
+	{input: `""/""/""`, values: []string{"\n\n"}, spaces: []string{""}},
+	{input: `"a"#c`, values: []string{"a"}, spaces: []string{""}, hash: true, comment: "c"},
+	{input: `"a" #c`, values: []string{"a"}, spaces: []string{"", " "}, hash: true, comment: "c"},
+	{input: `"a"#"b"`, values: []string{"a"}, spaces: []string{""}, hash: true, comment: `"b"`},
+	{input: `a "b" c`, values: []string{"a", "b", "c"}, spaces: []string{"", " ", " "}},
+	{input: `"a"`, values: []string{"a"}, spaces: []string{""}},
+	{input: `a "b c" d`, values: []string{"a", "b c", "d"}, spaces: []string{"", " ", " "}},
+	{input: `"a""b"/"c"`, values: []string{"a\"b\nc"}, spaces: []string{""}},
+	{input: `"-" 2024-01-02`, values: []string{"-", "2024-01-02"}, spaces: []string{"", " "}},
+	{input: `"𝄞" "" -`, values: []string{"𝄞", "", ""}, nulls: []int{2}, spaces: []string{"", " ", " "}},
+
+	// a closing quote ends the value
+	{input: `"a" b`, values: []string{"a", "b"}, spaces: []string{"", " "}},
+	{input: "\"a\"\t\"b\"", values: []string{"a", "b"}, spaces: []string{"", "\t"}},
+	{input: `"a" -`, values: []string{"a", ""}, nulls: []int{1}, spaces: []string{"", " "}},
+
+	// invalid lines
+	{input: `"a`, err: UnclosedString},
+	{input: `a "b`, err: UnclosedString, column: 2},
+	{input: `"a""`, err: UnclosedString},
+	{input: `"a"/`, err: UnclosedString},
+	{input: `"a"/"`, err: UnclosedString},
+	{input: `"a"b`, err: InvalidCharacterAfterString, column: 3},
+	{input: `"a""b"c`, err: InvalidCharacterAfterString, column: 6},
+	{input: `"a"-"b"`, err: InvalidCharacterAfterString, column: 3},
+	{input: `"a"/b`, err: InvalidCharacterAfterEscape, column: 4},
+	{input: `a"`, err: InvalidDoubleQuote, column: 1},
+	{input: `a"b"`, err: InvalidDoubleQuote, column: 1},
+	{input: `-"a"`, err: InvalidDoubleQuote, column: 1},
+}
+
+func TestConformance(t *testing.T) {
+	for _, test := range conformance {
+		l, err := ParseLine(test.input, true)
+		if test.err != 0 {
+			pe, ok := err.(*ParseError)
+			if !ok || pe.Kind != test.err || pe.Column != test.column {
+				t.Errorf("%q: expected %v at column %v, got %v", test.input, test.err, test.column, err)
+			}
+			if _, err := ParseLine(test.input, false); err == nil {
+				t.Errorf("%q: expected error without preserving", test.input)
+			}
+			continue
+		} else if err != nil {
+			t.Errorf("%q: unexpected error %v", test.input, err)
+			continue
+		}
+
+		values := test.values
+		if values == nil {
+			values = []string{}
+		}
+		nulls := []int{}
+		for i := range l.GetValues() {
+			if l.IsNil(i) {
+				nulls = append(nulls, i)
+			}
+		}
+		spaces := test.spaces
+		if spaces == nil {
+			spaces = []string{}
+		}
+		if !slices.Equal(l.GetValues(), values) {
+			t.Errorf("%q: expected values %q, got %q", test.input, values, l.GetValues())
+		}
+		if !slices.Equal(nulls, append([]int{}, test.nulls...)) {
+			t.Errorf("%q: expected nulls %v, got %v", test.input, test.nulls, nulls)
+		}
+		if !slices.Equal(l.GetSpaces(), spaces) {
+			t.Errorf("%q: expected spaces %q, got %q", test.input, spaces, l.GetSpaces())
+		}
+		if comment, hash := l.GetComment(); hash != test.hash || comment != test.comment {
+			t.Errorf("%q: expected comment %q, got %q", test.input, test.comment, comment)
+		}
+		// String rebuilds the line from its values, quoting, spaces and comment
+		if l.String() != test.input {
+			t.Errorf("%q: expected round trip, got %q", test.input, l.String())
+		}
+
+		// a line built from the same parts serializes to one that parses back
+		// to them
+		b := NewLine()
+		b.SetValues(values)
+		for _, i := range test.nulls {
+			b.SetNil(i)
+		}
+		if err := b.SetSpaces(spaces); err != nil {
+			t.Errorf("%q: unexpected error %v", test.input, err)
+		}
+		if test.hash {
+			b.SetComment(test.comment)
+		}
+		if p, err := ParseLine(b.String(), true); err != nil {
+			t.Errorf("%q: unexpected error %v", b.String(), err)
+		} else if !slices.Equal(p.GetValues(), values) || !slices.Equal(p.GetSpaces(), spaces) || p.ValuesString() != l.ValuesString() {
+			t.Errorf("%q: expected %q to parse back to %q", test.input, b.String(), values)
+		} else if comment, hash := p.GetComment(); hash != test.hash || comment != test.comment {
+			t.Errorf("%q: expected comment %q, got %q", test.input, test.comment, comment)
+		}
+
+		// the minimal form has the same values and parses back to them
+		m, err := ParseLine(test.input, false)
+		if err != nil {
+			t.Errorf("%q: unexpected error %v", test.input, err)
+			continue
+		}
+		if m.String() != l.ValuesString() {
+			t.Errorf("%q: expected minimal %q, got %q", test.input, l.ValuesString(), m.String())
+		}
+		r, err := ParseLine(m.String(), true)
+		if err != nil {
+			t.Errorf("%q: unexpected error %v", test.input, err)
+		} else if !slices.Equal(r.GetValues(), values) || r.ValuesString() != m.String() {
+			t.Errorf("%q: expected %q after round trip, got %q", test.input, values, r.GetValues())
+		}
+	}
+}
+
+func TestSerializeValue(t *testing.T) {
+	tests := []struct {
+		value    string
+		null     bool
+		expected string
+	}{
+		{"", true, "-"},
+		{"", false, `""`},
+		{"-", false, `"-"`},
+		{"--", false, "--"},
+		{"a", false, "a"},
+		{"a b", false, `"a b"`},
+		{"a　b", false, "\"a　b\""},
+		{"#", false, `"#"`},
+		{`a"b`, false, `"a""b"`},
+		{"a\nb", false, `"a"/"b"`},
+		{"𝄞", false, "𝄞"},
+	}
+	for _, test := range tests {
+		if s := SerializeValue(test.value, test.null); s != test.expected {
+			t.Errorf("%q: expected %q, got %q", test.value, test.expected, s)
+		}
+	}
+}
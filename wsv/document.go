@@ -0,0 +1,139 @@
+package wsv
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"strings"
+
+	"github.com/wjanssens/rtxt"
+)
+
+type Document struct {
+	Lines       []*Line
+	Encoding    rtxt.ReliableTxtEncoding
+	Compression rtxt.Compression
+	// NoBom is set for a UTF-8 document read without a byte order mark, which
+	// is then written without one too
+	NoBom bool
+}
+
+func NewDocument() *Document {
+	return &Document{
+		Lines:    make([]*Line, 0),
+		Encoding: rtxt.Utf8,
+	}
+}
+
+// ParseDocument parses the text of a document; when whitespace and comments are
+// preserved, String returns s unchanged
+func ParseDocument(s string, preserveWhitespaceAndComments bool) (*Document, error) {
+	d := NewDocument()
+	for i, text := range rtxt.Split(s) {
+		line, err := parseLine(text, preserveWhitespaceAndComments, i)
+		if err != nil {
+			return d, err
+		}
+		d.Lines = append(d.Lines, line)
+	}
+	return d, nil
+}
+
+func ReadDocument(r io.Reader, preserveWhitespaceAndComments bool) (*Document, error) {
+	return readDocument(r, preserveWhitespaceAndComments, nil)
+}
+
+func readDocument(r io.Reader, preserveWhitespaceAndComments bool, onError func(text string, err *ParseError) *Line) (*Document, error) {
+	d := NewDocument()
+	rd := rtxt.NewReader(r)
+	err := parseEach(context.Background(), rd, preserveWhitespaceAndComments, 0, nil, onError, func(lineIndex int, line *Line) error {
+		d.Lines = append(d.Lines, line)
+		return nil
+	})
+	if err != nil {
+		return d, err
+	}
+	d.Encoding, _ = rd.Encoding()
+	d.Compression, _ = rd.Compression()
+	bom, _ := rd.HasBom()
+	d.NoBom = !bom
+	return d, nil
+}
+
+func LoadDocument(path string, preserveWhitespaceAndComments bool) (*Document, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return ReadDocument(f, preserveWhitespaceAndComments)
+}
+
+func LoadDocumentFS(fsys fs.FS, name string, preserveWhitespaceAndComments bool) (*Document, error) {
+	f, err := fsys.Open(name)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return ReadDocument(f, preserveWhitespaceAndComments)
+}
+
+func (d *Document) Len() int {
+	return len(d.Lines)
+}
+func (d *Document) AddLine(l *Line) {
+	d.Lines = append(d.Lines, l)
+}
+func (d *Document) InsertLine(i int, l *Line) error {
+	if i < 0 || i > len(d.Lines) {
+		return fmt.Errorf("Line index %v out of range", i)
+	}
+	d.Lines = append(d.Lines, nil)
+	copy(d.Lines[i+1:], d.Lines[i:])
+	d.Lines[i] = l
+	return nil
+}
+func (d *Document) RemoveLine(i int) error {
+	if i < 0 || i >= len(d.Lines) {
+		return fmt.Errorf("Line index %v out of range", i)
+	}
+	d.Lines = append(d.Lines[:i], d.Lines[i+1:]...)
+	return nil
+}
+
+func (d *Document) String() string {
+	result := make([]string, 0, len(d.Lines))
+	for _, l := range d.Lines {
+		result = append(result, l.String())
+	}
+	return strings.Join(result, "\n")
+}
+
+// WriteTo writes the document in its encoding, including the byte order mark
+// unless NoBom is set
+func (d *Document) WriteTo(w io.Writer) (int64, error) {
+	e := rtxt.NewWriter(w, d.Encoding)
+	e.NoBom = d.NoBom
+	for _, l := range d.Lines {
+		if _, err := e.WriteLine(l.String()); err != nil {
+			return e.Written(), err
+		}
+	}
+	err := e.Close()
+	return e.Written(), err
+}
+
+func (d *Document) Save(path string) error {
+	return d.SaveWithOptions(path, nil)
+}
+
+// SaveWithOptions saves the document with rtxt.WriteFile; it is compressed with
+// d.Compression unless opts sets a compression
+func (d *Document) SaveWithOptions(path string, opts *rtxt.SaveOptions) error {
+	return rtxt.WriteFile(path, opts.WithCompression(d.Compression), func(w io.Writer) error {
+		_, err := d.WriteTo(w)
+		return err
+	})
+}
@@ -0,0 +1,110 @@
+package wsv
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/wjanssens/rtxt"
+)
+
+func TestDocumentRoundTrip(t *testing.T) {
+	valid := []string{
+		"",
+		"a b\n  c  #comment\n",
+		"\"a\"  \"b c\" -\t\"\"\r\n\"x\"\"y\"/\"z\"",
+		"#only a comment\n\n\n",
+		"　a -",
+	}
+	for _, enc := range []rtxt.ReliableTxtEncoding{rtxt.Utf8, rtxt.Utf16, rtxt.Utf16Reverse, rtxt.Utf32} {
+		for i, s := range valid {
+			var buf bytes.Buffer
+			rtxt.WriteLines(&buf, rtxt.Split(s), enc)
+			expected := buf.Bytes()
+
+			d, err := ReadDocument(bytes.NewReader(expected), true)
+			if err != nil {
+				t.Errorf("%v %v: unexpected error %v", enc, i, err)
+				continue
+			}
+			if d.Encoding != enc {
+				t.Errorf("%v %v: expected encoding %v", enc, i, d.Encoding)
+			}
+			if d.String() != s {
+				t.Errorf("%v %v: expected %q, got %q", enc, i, s, d.String())
+			}
+			var out bytes.Buffer
+			if _, err := d.WriteTo(&out); err != nil {
+				t.Errorf("%v %v: unexpected error %v", enc, i, err)
+			}
+			if !bytes.Equal(out.Bytes(), expected) {
+				t.Errorf("%v %v: expected %x, got %x", enc, i, expected, out.Bytes())
+			}
+		}
+	}
+	for i, s := range valid {
+		d, err := ReadDocument(strings.NewReader(s), true)
+		if err != nil {
+			t.Errorf("%v: unexpected error %v", i, err)
+			continue
+		}
+		if !d.NoBom {
+			t.Errorf("%v: expected a document without a byte order mark", i)
+		}
+		var out bytes.Buffer
+		if _, err := d.WriteTo(&out); err != nil {
+			t.Errorf("%v: unexpected error %v", i, err)
+		}
+		if out.String() != s {
+			t.Errorf("%v: expected %q, got %q", i, s, out.String())
+		}
+	}
+}
+
+func TestDocumentLines(t *testing.T) {
+	d, _ := ParseDocument("a\nb", true)
+	c, _ := ParseLine("c", true)
+	d.AddLine(c)
+	x, _ := ParseLine("x", true)
+	if err := d.InsertLine(0, x); err != nil {
+		t.Errorf("unexpected error %v", err)
+	}
+	if err := d.RemoveLine(2); err != nil {
+		t.Errorf("unexpected error %v", err)
+	}
+	if d.String() != "x\na\nc" {
+		t.Errorf("expected x a c, got %q", d.String())
+	}
+	if d.InsertLine(5, x) == nil || d.RemoveLine(3) == nil || d.RemoveLine(-1) == nil {
+		t.Errorf("expected out of range errors")
+	}
+	d.Lines[1].SetValue(0, "a b")
+	if d.String() != "x\n\"a b\"\nc" {
+		t.Errorf("expected modified line to be serialized, got %q", d.String())
+	}
+}
+
+func TestDocumentLoadSave(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "test.wsv.gz")
+	d, _ := ParseDocument("a  b # c", true)
+	d.Encoding = rtxt.Utf16
+	gz := rtxt.Gzip
+	if err := d.SaveWithOptions(path, &rtxt.SaveOptions{Compression: &gz}); err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	l, err := LoadDocument(path, true)
+	if err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	if l.String() != d.String() || l.Encoding != rtxt.Utf16 || l.Compression != rtxt.Gzip {
+		t.Errorf("unexpected document %q %v %v", l.String(), l.Encoding, l.Compression)
+	}
+
+	l.Compression = rtxt.NoCompression
+	l.Save(path)
+	if b, _ := os.ReadFile(path); !bytes.HasPrefix(b, []byte{0xfe, 0xff}) {
+		t.Errorf("expected uncompressed UTF-16, got %x", b)
+	}
+}
@@ -0,0 +1,53 @@
+package wsv
+
+import (
+	"errors"
+	"fmt"
+)
+
+// ErrInvalidLine is returned when modifying a line that failed to parse and
+// was kept by lenient parsing
+var ErrInvalidLine = errors.New("Line is invalid and cannot be modified")
+
+type ErrorKind int
+
+const (
+	// UnclosedString is a quoted string without a closing quote
+	UnclosedString ErrorKind = 1
+	// InvalidCharacterAfterString is a character other than whitespace or a
+	// hash directly after a quoted string
+	InvalidCharacterAfterString ErrorKind = 2
+	// InvalidCharacterAfterEscape is a "/ escape not followed by a quote
+	InvalidCharacterAfterEscape ErrorKind = 3
+	// InvalidDoubleQuote is a quote inside an unquoted value
+	InvalidDoubleQuote ErrorKind = 4
+)
+
+func (k ErrorKind) String() string {
+	switch k {
+	case UnclosedString:
+		return "Quoted string not closed"
+	case InvalidCharacterAfterString:
+		return "Invalid character after string"
+	case InvalidCharacterAfterEscape:
+		return "Invalid character after escaped character"
+	case InvalidDoubleQuote:
+		return "Invalid double quote in value"
+	default:
+		return fmt.Sprintf("ErrorKind(%d)", int(k))
+	}
+}
+
+// ParseError is returned for malformed lines. Column counts code points and
+// Offset counts bytes of the UTF-8 line text, both 0-based from the start of
+// the line.
+type ParseError struct {
+	LineIndex int
+	Column    int
+	Offset    int
+	Kind      ErrorKind
+}
+
+func (e *ParseError) Error() string {
+	return fmt.Sprintf("Line %v, column %v: %v", e.LineIndex, e.Column, e.Kind)
+}
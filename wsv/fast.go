@@ -0,0 +1,189 @@
+package wsv
+
+import (
+	"strings"
+	"sync"
+	"unicode/utf8"
+)
+
+// valueParser parses only the values of a line, dropping whitespace and
+// comments. Values are unescaped back to back into buf; its buffers are reused
+// from line to line so that parsing a line does not allocate once they have
+// grown.
+type valueParser struct {
+	in    []byte // copy of a line given as a string
+	buf   []byte
+	ends  []int // end of each value in buf
+	nulls bitset
+	// chunk holds the values of recent lines as text; what was written to it is
+	// never overwritten, so strings taken from it stay valid
+	chunk strings.Builder
+}
+
+// chunkSize is the size of the chunks values are copied into, shared by the
+// lines that fit
+const chunkSize = 4096
+
+// parsers are reused by ParseLine
+var parsers = sync.Pool{New: func() any { return new(valueParser) }}
+
+func (p *valueParser) parseString(l string, lineIndex int) error {
+	p.in = append(p.in[:0], l...)
+	return p.parse(p.in, lineIndex)
+}
+
+func (p *valueParser) parse(l []byte, lineIndex int) error {
+	p.buf = p.buf[:0]
+	p.ends = p.ends[:0]
+	clear(p.nulls)
+
+	fail := func(kind ErrorKind, column, offset int) error {
+		return &ParseError{LineIndex: lineIndex, Column: column, Offset: offset, Kind: kind}
+	}
+
+	i, column := 0, 0
+	for i < len(l) {
+		// skip whitespace
+		if c := l[i]; c < utf8.RuneSelf {
+			if asciiWs[c] {
+				i++
+				column++
+				continue
+			}
+		} else if r, n := utf8.DecodeRune(l[i:]); isWs(r) {
+			i += n
+			column++
+			continue
+		}
+
+		switch l[i] {
+		case 0x0023: // hash
+			return nil
+		case 0x0022: // quote
+			quoteColumn, quoteOffset := column, i
+			i++
+			column++
+		quoted:
+			for {
+				if i >= len(l) {
+					return fail(UnclosedString, quoteColumn, quoteOffset)
+				}
+				c := l[i]
+				switch {
+				case c == 0x0022 && i+1 < len(l) && l[i+1] == 0x0022:
+					// "...""..." is a quote
+					p.buf = append(p.buf, c)
+					i += 2
+					column += 2
+				case c == 0x0022 && i+1 < len(l) && l[i+1] == 0x002f:
+					// "..."/"..." is a LF
+					i += 2
+					column += 2
+					if i >= len(l) {
+						return fail(UnclosedString, quoteColumn, quoteOffset)
+					} else if l[i] != 0x0022 {
+						return fail(InvalidCharacterAfterEscape, column, i)
+					}
+					p.buf = append(p.buf, 0x000a)
+					i++
+					column++
+				case c == 0x0022:
+					i++
+					column++
+					break quoted
+				case c < utf8.RuneSelf:
+					p.buf = append(p.buf, c)
+					i++
+					column++
+				default:
+					r, n := utf8.DecodeRune(l[i:])
+					p.buf = utf8.AppendRune(p.buf, r)
+					i += n
+					column++
+				}
+			}
+			// the string must be followed by whitespace, a comment or the end
+			if i < len(l) {
+				ok := asciiWs[l[i]&0x7f] || l[i] == 0x0023
+				if l[i] >= utf8.RuneSelf {
+					r, _ := utf8.DecodeRune(l[i:])
+					ok = isWs(r)
+				}
+				if !ok {
+					return fail(InvalidCharacterAfterString, column, i)
+				}
+			}
+			p.ends = append(p.ends, len(p.buf))
+		default:
+			start := len(p.buf)
+		unquoted:
+			for i < len(l) {
+				c := l[i]
+				if c < utf8.RuneSelf {
+					if asciiWs[c] || c == 0x0023 {
+						break unquoted
+					} else if c == 0x0022 {
+						return fail(InvalidDoubleQuote, column, i)
+					}
+					p.buf = append(p.buf, c)
+					i++
+				} else {
+					r, n := utf8.DecodeRune(l[i:])
+					if isWs(r) {
+						break unquoted
+					}
+					p.buf = utf8.AppendRune(p.buf, r)
+					i += n
+				}
+				column++
+			}
+			if len(p.buf)-start == 1 && p.buf[start] == 0x002d {
+				// a lone dash is null
+				p.buf = p.buf[:start]
+				p.nulls.set(len(p.ends))
+			}
+			p.ends = append(p.ends, len(p.buf))
+		}
+	}
+	return nil
+}
+
+// text returns the parsed values back to back, copied into the current chunk;
+// a new chunk is only allocated when the values do not fit
+func (p *valueParser) text() string {
+	if p.chunk.Cap()-p.chunk.Len() < len(p.buf) {
+		p.chunk.Reset()
+		p.chunk.Grow(max(chunkSize, len(p.buf)))
+	}
+	start := p.chunk.Len()
+	p.chunk.Write(p.buf)
+	return p.chunk.String()[start:]
+}
+
+// values slices s, the text of the parsed values, into record, reusing its
+// storage
+func (p *valueParser) values(record []string, s string) []string {
+	record = record[:0]
+	start := 0
+	for _, end := range p.ends {
+		record = append(record, s[start:end])
+		start = end
+	}
+	return record
+}
+
+// line builds a minimal line, without spaces, from s, the text of the parsed
+// values
+func (p *valueParser) line(s string) *Line {
+	line := &Line{}
+	if len(p.ends) == 0 {
+		return line
+	}
+	line.values = p.values(make([]string, 0, len(p.ends)), s)
+	for i := range line.values {
+		if p.nulls.has(i) {
+			line.nulls.set(i)
+		}
+	}
+	return line
+}
@@ -0,0 +1,109 @@
+package wsv
+
+import (
+	"strings"
+	"unicode"
+
+	"golang.org/x/text/width"
+)
+
+type Alignment int
+
+const (
+	AlignLeft  Alignment = 0
+	AlignRight Alignment = 1
+)
+
+// Formatter aligns the values of lines into columns by rewriting the whitespace
+// between them; values and comments are left unchanged.
+type Formatter struct {
+	// Spacing separates columns and defaults to a single space
+	Spacing string
+	// Align gives the alignment of each column; missing columns align left
+	Align []Alignment
+	// MaxColumns stops aligning after that many columns when positive
+	MaxColumns int
+	// EastAsianWidth measures values by display width, counting wide and
+	// fullwidth characters as two and combining marks as zero. Otherwise every
+	// code point counts as one.
+	EastAsianWidth bool
+}
+
+// Format aligns lines in place. Leading whitespace of each line is kept; lines
+// without values are left as they are.
+func (f *Formatter) Format(lines []*Line) error {
+	spacing := f.Spacing
+	if spacing == "" {
+		spacing = " "
+	}
+	if err := ValidateSpace(spacing, false); err != nil {
+		return err
+	}
+
+	widths := make([]int, 0)
+	for _, l := range lines {
+		for c := range l.values {
+			w := f.width(l.serializeValue(c))
+			if c >= len(widths) {
+				widths = append(widths, 0)
+			}
+			if w > widths[c] {
+				widths[c] = w
+			}
+		}
+	}
+
+	for _, l := range lines {
+		if !l.HasValues() {
+			continue
+		}
+		n := len(l.values)
+		spaces := make([]string, n+1)
+		if len(l.spaces) > 0 {
+			spaces[0] = l.spaces[0]
+		}
+		for c := 0; c < n; c++ {
+			pad := ""
+			if f.MaxColumns <= 0 || c < f.MaxColumns {
+				pad = strings.Repeat(" ", widths[c]-f.width(l.serializeValue(c)))
+			}
+			if f.alignment(c) == AlignRight {
+				spaces[c] += pad
+			} else if c+1 < n || l.hash {
+				spaces[c+1] += pad
+			}
+			if c+1 < n || l.hash {
+				spaces[c+1] += spacing
+			}
+		}
+		if !l.hash {
+			spaces = spaces[:n]
+		}
+		l.spaces = spaces
+	}
+	return nil
+}
+
+func (f *Formatter) alignment(c int) Alignment {
+	if c < len(f.Align) {
+		return f.Align[c]
+	}
+	return AlignLeft
+}
+
+func (f *Formatter) width(s string) int {
+	if !f.EastAsianWidth {
+		return len([]rune(s))
+	}
+	w := 0
+	for _, r := range s {
+		switch {
+		case unicode.Is(unicode.Mn, r) || unicode.Is(unicode.Me, r):
+		case width.LookupRune(r).Kind() == width.EastAsianWide || width.LookupRune(r).Kind() == width.EastAsianFullwidth:
+			w += 2
+		default:
+			w++
+		}
+	}
+	return w
+}
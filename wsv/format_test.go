@@ -0,0 +1,46 @@
+package wsv
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestFormat(t *testing.T) {
+	type test struct {
+		formatter Formatter
+		input     string
+		expected  string
+	}
+	tests := []test{
+		{Formatter{}, "a bb c\naaa b\n# comment\nx - \"y z\" #c", "a   bb c\naaa b\n# comment\nx   -  \"y z\" #c"},
+		{Formatter{Align: []Alignment{AlignRight, AlignRight}}, "a 1\nbbb 22\ncc 333", "  a   1\nbbb  22\n cc 333"},
+		{Formatter{Spacing: "\t"}, "a b\naa b", "a \tb\naa\tb"},
+		{Formatter{MaxColumns: 1}, "a bb c\naaa b c", "a   bb c\naaa b c"},
+		{Formatter{}, "東京 x\nab x", "東京 x\nab x"},
+		{Formatter{EastAsianWidth: true}, "東京 x\nab x\ne\u0301 x", "東京 x\nab   x\ne\u0301    x"},
+		{Formatter{}, "  a b\n  aa b", "  a  b\n  aa b"},
+	}
+	for i, test := range tests {
+		d, err := ParseDocument(test.input, true)
+		if err != nil {
+			t.Fatalf("%v: unexpected error %v", i, err)
+		}
+		if err := test.formatter.Format(d.Lines); err != nil {
+			t.Errorf("%v: unexpected error %v", i, err)
+		}
+		if d.String() != test.expected {
+			t.Errorf("%v: expected\n%v\ngot\n%v", i, strings.ReplaceAll(test.expected, "\t", "→"), strings.ReplaceAll(d.String(), "\t", "→"))
+		}
+		reparsed, _ := ParseDocument(d.String(), false)
+		original, _ := ParseDocument(test.input, false)
+		for j, l := range reparsed.Lines {
+			if l.ValuesString() != original.Lines[j].ValuesString() {
+				t.Errorf("%v: values changed: %q != %q", i, l.ValuesString(), original.Lines[j].ValuesString())
+			}
+		}
+	}
+
+	if err := (&Formatter{Spacing: "x"}).Format(nil); err == nil {
+		t.Errorf("expected invalid spacing error")
+	}
+}
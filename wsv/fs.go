@@ -0,0 +1,31 @@
+package wsv
+
+import (
+	"io/fs"
+
+	"github.com/wjanssens/rtxt"
+)
+
+func LoadFS(fsys fs.FS, name string, preserveWhitespaceAndComments bool) ([]Line, error) {
+	f, err := fsys.Open(name)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return Parse(f, preserveWhitespaceAndComments, 0)
+}
+
+// LoadGlobFS parses every file in fsys matching pattern, for example
+// "conf/*.wsv", and returns the lines by file name
+func LoadGlobFS(fsys fs.FS, pattern string, preserveWhitespaceAndComments bool) (map[string][]Line, error) {
+	return rtxt.LoadGlobFS(fsys, pattern, func(fsys fs.FS, name string) ([]Line, error) {
+		return LoadFS(fsys, name, preserveWhitespaceAndComments)
+	})
+}
+
+// LoadDocumentGlobFS is like LoadGlobFS but returns documents
+func LoadDocumentGlobFS(fsys fs.FS, pattern string, preserveWhitespaceAndComments bool) (map[string]*Document, error) {
+	return rtxt.LoadGlobFS(fsys, pattern, func(fsys fs.FS, name string) (*Document, error) {
+		return LoadDocumentFS(fsys, name, preserveWhitespaceAndComments)
+	})
+}
@@ -0,0 +1,47 @@
+package wsv
+
+import (
+	"testing"
+	"testing/fstest"
+
+	"github.com/wjanssens/rtxt"
+)
+
+func TestLoadFS(t *testing.T) {
+	fsys := fstest.MapFS{
+		"conf/a.wsv":   {Data: []byte("a b\nc")},
+		"conf/b.wsv":   {Data: []byte("\xef\xbb\xbfd # comment")},
+		"conf/c.txt":   {Data: []byte("e")},
+		"conf/bad.wsx": {Data: []byte("\"f")},
+	}
+	lines, err := LoadFS(fsys, "conf/a.wsv", true)
+	if err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	if len(lines) != 2 || lines[0].String() != "a b" {
+		t.Errorf("unexpected lines %v", lines)
+	}
+
+	files, err := LoadGlobFS(fsys, "conf/*.wsv", false)
+	if err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	if len(files) != 2 || files["conf/b.wsv"][0].ValuesString() != "d" {
+		t.Errorf("unexpected files %v", files)
+	}
+
+	if _, err := LoadGlobFS(fsys, "conf/*.wsx", false); err == nil {
+		t.Errorf("expected parse error")
+	}
+
+	docs, err := LoadDocumentGlobFS(fsys, "conf/*.wsv", true)
+	if err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	if d := docs["conf/b.wsv"]; len(docs) != 2 || d.Encoding != rtxt.Utf8 || d.String() != "d # comment" {
+		t.Errorf("unexpected documents %v", docs)
+	}
+	if _, err := LoadDocumentGlobFS(fsys, "conf/*.wsx", false); err == nil {
+		t.Errorf("expected parse error")
+	}
+}
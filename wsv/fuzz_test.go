@@ -0,0 +1,87 @@
+package wsv
+
+import (
+	"slices"
+	"strings"
+	"testing"
+	"unicode/utf8"
+)
+
+func FuzzParseLine(f *testing.F) {
+	for _, test := range conformance {
+		f.Add(test.input)
+	}
+	f.Fuzz(func(t *testing.T, s string) {
+		if !utf8.ValidString(s) || strings.Contains(s, "\n") {
+			return
+		}
+		l, err := ParseLine(s, true)
+		m, merr := ParseLine(s, false)
+		if (err == nil) != (merr == nil) {
+			t.Fatalf("preserving gave %v, minimal gave %v", err, merr)
+		}
+		if err != nil {
+			pe, ok := err.(*ParseError)
+			if !ok {
+				t.Fatalf("expected ParseError, got %v", err)
+			} else if mpe, ok := merr.(*ParseError); !ok || *mpe != *pe {
+				t.Fatalf("preserving gave %v, minimal gave %v", err, merr)
+			}
+			return
+		}
+		if !slices.Equal(m.GetValues(), l.GetValues()) {
+			t.Fatalf("preserving gave %q, minimal gave %q", l.GetValues(), m.GetValues())
+		}
+		// the line is serialized from its values, quoting, spaces and comment
+		if l.String() != s {
+			t.Fatalf("expected %q, got %q", s, l.String())
+		}
+
+		// serializing is stable
+		serialized := l.String()
+		p, err := ParseLine(serialized, true)
+		if err != nil {
+			t.Fatalf("%q: unexpected error %v", serialized, err)
+		}
+		if p.String() != serialized {
+			t.Fatalf("expected %q, got %q", serialized, p.String())
+		}
+
+		// values survive the minimal form
+		minimal := m.String()
+		r, err := ParseLine(minimal, false)
+		if err != nil {
+			t.Fatalf("%q: unexpected error %v", minimal, err)
+		}
+		if !slices.Equal(r.GetValues(), l.GetValues()) || r.String() != minimal {
+			t.Fatalf("expected %q, got %q", l.GetValues(), r.GetValues())
+		}
+		for i := range r.GetValues() {
+			if r.IsNil(i) != l.IsNil(i) {
+				t.Fatalf("null %v differs", i)
+			}
+		}
+	})
+}
+
+func FuzzParse(f *testing.F) {
+	f.Add("a b\n\"c\"#d\n-")
+	f.Add("\uFEFF\"a\"/\"b\"\n\n")
+	f.Fuzz(func(t *testing.T, s string) {
+		lines, err := Parse(strings.NewReader(s), true, 0)
+		if err != nil {
+			return
+		}
+		serialized := Serialize(lines)
+		if utf8.ValidString(s) && !strings.HasPrefix(s, "\uFEFF") && serialized != s {
+			t.Fatalf("expected %q, got %q", s, serialized)
+		}
+		again, err := Parse(strings.NewReader(serialized), true, 0)
+		if err != nil {
+			t.Fatalf("%q: unexpected error %v", serialized, err)
+		}
+		if Serialize(again) != serialized {
+			t.Fatalf("expected %q, got %q", serialized, Serialize(again))
+		}
+	})
+}
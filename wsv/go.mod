@@ -1,3 +1,5 @@
 module github.com/wjanssens/wsv
 
 go 1.21.5
+
+require golang.org/x/text v0.14.0
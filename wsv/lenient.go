@@ -0,0 +1,57 @@
+package wsv
+
+import (
+	"context"
+	"io"
+
+	"github.com/wjanssens/rtxt"
+)
+
+// Recovery chooses what lenient parsing does with a line that fails to parse
+type Recovery int
+
+const (
+	// RecoverRaw keeps the line as read-only text without values; String
+	// returns the text unchanged and IsInvalid reports true
+	RecoverRaw Recovery = 0
+	// RecoverSkip leaves the line out
+	RecoverSkip Recovery = 1
+)
+
+func (rc Recovery) line(text string) *Line {
+	if rc == RecoverSkip {
+		return nil
+	}
+	line := NewLine()
+	line.invalid = true
+	line.text = text
+	return line
+}
+
+// ParseLenient parses every line of r, collecting the errors of malformed lines
+// instead of stopping at the first one. The returned error is only set for
+// failures that end parsing, such as invalid encoding.
+func ParseLenient(r io.Reader, preserveWhitespaceAndComments bool, lineIndexOffset int, recovery Recovery) ([]Line, []*ParseError, error) {
+	lines := make([]Line, 0)
+	errs := make([]*ParseError, 0)
+	onError := func(text string, err *ParseError) *Line {
+		errs = append(errs, err)
+		return recovery.line(text)
+	}
+	err := parseEach(context.Background(), rtxt.NewReader(r), preserveWhitespaceAndComments, lineIndexOffset, nil, onError, func(lineIndex int, line *Line) error {
+		lines = append(lines, *line)
+		return nil
+	})
+	return lines, errs, err
+}
+
+// ReadDocumentLenient reads a document like ReadDocument, collecting the errors
+// of malformed lines like ParseLenient
+func ReadDocumentLenient(r io.Reader, preserveWhitespaceAndComments bool, recovery Recovery) (*Document, []*ParseError, error) {
+	errs := make([]*ParseError, 0)
+	d, err := readDocument(r, preserveWhitespaceAndComments, func(text string, err *ParseError) *Line {
+		errs = append(errs, err)
+		return recovery.line(text)
+	})
+	return d, errs, err
+}
@@ -0,0 +1,78 @@
+package wsv
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestParseLenient(t *testing.T) {
+	const input = "a b\n\"x\"y  # bad\nc\nd \"e"
+
+	lines, errs, err := ParseLenient(strings.NewReader(input), true, 0, RecoverRaw)
+	if err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	if len(lines) != 4 || Serialize(lines) != input {
+		t.Errorf("expected %q, got %q", input, Serialize(lines))
+	}
+	if lines[1].HasValues() || !lines[1].IsInvalid() || lines[1].Text() != "\"x\"y  # bad" {
+		t.Errorf("expected invalid line without values")
+	}
+	if len(errs) != 2 || errs[0].LineIndex != 1 || errs[0].Kind != InvalidCharacterAfterString || errs[1].LineIndex != 3 || errs[1].Kind != UnclosedString {
+		t.Errorf("unexpected errors %v", errs)
+	}
+
+	lines, errs, err = ParseLenient(strings.NewReader(input), false, 5, RecoverSkip)
+	if err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	if Serialize(lines) != "a b\nc" {
+		t.Errorf("expected %q, got %q", "a b\nc", Serialize(lines))
+	}
+	if len(errs) != 2 || errs[0].LineIndex != 6 || errs[1].LineIndex != 8 {
+		t.Errorf("unexpected errors %v", errs)
+	}
+}
+
+func TestInvalidLineEdit(t *testing.T) {
+	const text = "\"x\"y  # bad"
+	tests := []func(l *Line) error{
+		func(l *Line) error { l.SetValues([]string{"a"}); return nil },
+		func(l *Line) error { l.SetValue(0, "a"); return nil },
+		func(l *Line) error { l.SetInt(1, 2); return nil },
+		func(l *Line) error { l.Insert(0, "a"); return nil },
+		func(l *Line) error { l.Append("a"); return nil },
+		func(l *Line) error { l.Remove(0); return nil },
+		func(l *Line) error { l.Truncate(0); return nil },
+		func(l *Line) error { l.SetNil(0); return nil },
+		func(l *Line) error { l.UnsetNil(0); return nil },
+		func(l *Line) error { l.ClearComment(); return nil },
+		func(l *Line) error { return l.SetSpaces([]string{" "}) },
+		func(l *Line) error { return l.SetSpace(0, " ") },
+		func(l *Line) error { return l.SetComment("c") },
+	}
+	for i, edit := range tests {
+		lines, _, err := ParseLenient(strings.NewReader(text), true, 0, RecoverRaw)
+		if err != nil {
+			t.Fatalf("unexpected error %v", err)
+		}
+		l := &lines[0]
+		err = edit(l)
+		if i >= 10 && err != ErrInvalidLine {
+			t.Errorf("%v: expected ErrInvalidLine, got %v", i, err)
+		}
+		if !l.IsInvalid() || l.HasValues() || l.HasSpaces() || l.IsNil(0) || l.String() != text {
+			t.Errorf("%v: expected invalid line to be unchanged, got %q", i, l.String())
+		}
+	}
+}
+
+func TestReadDocumentLenient(t *testing.T) {
+	d, errs, err := ReadDocumentLenient(strings.NewReader("\uFEFFa\n\"b"), true, RecoverRaw)
+	if err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	if len(errs) != 1 || d.Len() != 2 || d.String() != "a\n\"b" {
+		t.Errorf("unexpected document %q with errors %v", d.String(), errs)
+	}
+}
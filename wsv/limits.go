@@ -0,0 +1,39 @@
+package wsv
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/wjanssens/rtxt"
+)
+
+var (
+	ErrTooManyLines  = errors.New("Too many lines")
+	ErrLineTooLong   = rtxt.ErrLineTooLong
+	ErrTooManyValues = errors.New("Too many values")
+)
+
+// Limits guards parsing of untrusted input; a zero field means no limit
+type Limits struct {
+	MaxLines int
+	// MaxLineLength is the encoded length of a line in bytes
+	MaxLineLength int
+	// MaxValues is the number of values on a single line
+	MaxValues int
+}
+
+// LimitError reports the line at which a limit was exceeded; Err is one of
+// ErrTooManyLines, ErrLineTooLong or ErrTooManyValues
+type LimitError struct {
+	LineIndex int
+	Limit     int
+	Err       error
+}
+
+func (e *LimitError) Error() string {
+	return fmt.Sprintf("Line %v: %v (limit %v)", e.LineIndex, e.Err, e.Limit)
+}
+
+func (e *LimitError) Unwrap() error {
+	return e.Err
+}
@@ -0,0 +1,60 @@
+package wsv
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestParseContextLimits(t *testing.T) {
+	type test struct {
+		input  string
+		limits Limits
+		err    error
+		line   int
+	}
+	tests := []test{
+		{"a\nb\nc", Limits{MaxLines: 3}, nil, 0},
+		{"a\nb\nc", Limits{MaxLines: 2}, ErrTooManyLines, 2},
+		{"a b\nc d e", Limits{MaxValues: 3}, nil, 0},
+		{"a b\nc d e f", Limits{MaxValues: 3}, ErrTooManyValues, 1},
+		{"abc\nabcd", Limits{MaxLineLength: 4}, nil, 0},
+		{"abc\nabcde", Limits{MaxLineLength: 4}, ErrLineTooLong, 1},
+		{"abc\n" + strings.Repeat("a", 10000), Limits{MaxLineLength: 4}, ErrLineTooLong, 1},
+	}
+	for i, test := range tests {
+		_, err := ParseContext(context.Background(), strings.NewReader(test.input), true, 0, &test.limits)
+		if test.err == nil {
+			if err != nil {
+				t.Errorf("%v: unexpected error %v", i, err)
+			}
+			continue
+		}
+		var le *LimitError
+		if !errors.As(err, &le) || !errors.Is(err, test.err) || le.LineIndex != test.line {
+			t.Errorf("%v: expected %v at line %v, got %v", i, test.err, test.line, err)
+		}
+	}
+}
+
+func TestParseContextCancel(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := ParseContext(ctx, strings.NewReader("a"), true, 0, nil); !errors.Is(err, context.Canceled) {
+		t.Errorf("expected cancellation, got %v", err)
+	}
+
+	ctx, cancel = context.WithCancel(context.Background())
+	input := strings.Repeat("a b c\n", 10*contextCheckInterval)
+	n := 0
+	err := ParseEach(ctx, strings.NewReader(input), true, 0, nil, func(lineIndex int, line *Line) error {
+		if n++; n == contextCheckInterval+1 {
+			cancel()
+		}
+		return nil
+	})
+	if !errors.Is(err, context.Canceled) || n != 2*contextCheckInterval {
+		t.Errorf("expected cancellation after %v lines, got %v after %v", 2*contextCheckInterval, err, n)
+	}
+}
@@ -2,7 +2,8 @@ package wsv
 
 import (
 	"bytes"
-	"math/big"
+	"fmt"
+	"slices"
 	"strings"
 )
 
@@ -39,19 +40,27 @@ func SerializeValue(s string, isNull bool) string {
 }
 
 type Line struct {
-	// nulls and values always have the same length
-	// spaces always has one more element than values (space before, spaces between values, space between value and comment)
+	// nulls only has members below len(values)
+	// quoted only has members below len(values); it marks values that were
+	// parsed from a quoted string, which String writes quoted again
+	// spaces has at most one more element than values (space before each value,
+	// then space between the last value and the comment); missing spaces are
+	// written as a single space
 	values  []string
-	nulls   *big.Int
+	nulls   bitset
+	quoted  bitset
 	spaces  []string
 	hash    bool
 	comment string
+	// invalid marks a line that failed to parse and was kept by lenient parsing;
+	// text is its original text, which String returns, and the line is read-only
+	invalid bool
+	text    string
 }
 
 func NewLine() *Line {
 	return &Line{
 		values:  make([]string, 0),
-		nulls:   big.NewInt(0),
 		spaces:  make([]string, 0),
 		hash:    false,
 		comment: "",
@@ -63,44 +72,195 @@ func (l *Line) Len() int {
 func (l *Line) HasValues() bool {
 	return l.values != nil && len(l.values) > 0
 }
+
+// GetValues returns a copy of the values
 func (l *Line) GetValues() []string {
-	return l.values
+	return slices.Clone(l.values)
+}
+
+// GetValue returns value i, or "" when i is out of range
+func (l *Line) GetValue(i int) string {
+	if i < 0 || i >= len(l.values) {
+		return ""
+	}
+	return l.values[i]
 }
+
+// SetValues replaces all values with non-null ones, dropping spaces that no
+// longer have a value
 func (l *Line) SetValues(values []string) {
-	l.values = values
+	if l.invalid {
+		return
+	}
+	l.fitSpaces(len(l.values), len(values))
+	l.values = append(make([]string, 0, len(values)), values...)
+	clear(l.nulls)
+	clear(l.quoted)
 }
+
+// SetValue sets value i and makes it non-null, adding empty values to reach
+// index i; a negative index is ignored
 func (l *Line) SetValue(i int, value string) {
+	if i < 0 || l.invalid {
+		return
+	}
+	l.grow(i + 1)
 	l.values[i] = value
+	l.nulls.unset(i)
+	l.quoted.unset(i)
+}
+
+// Insert inserts values before index i, or appends them when i is the length;
+// an index out of range is ignored
+func (l *Line) Insert(i int, values ...string) {
+	if i < 0 || i > len(l.values) || l.invalid {
+		return
+	}
+	for n, v := range values {
+		j := i + n
+		if len(l.spaces) > j && j == len(l.values) {
+			// an appended value gets a single space and the space before the
+			// comment stays last
+			space := " "
+			if j == 0 {
+				space = ""
+			}
+			l.spaces = slices.Insert(l.spaces, j, space)
+		} else if len(l.spaces) > j {
+			// the new value takes the space before it and is separated from the
+			// value it was inserted before
+			l.spaces = slices.Insert(l.spaces, j+1, " ")
+		}
+		l.values = slices.Insert(l.values, j, v)
+		l.nulls.insert(j)
+		l.quoted.insert(j)
+	}
+}
+
+func (l *Line) Append(values ...string) {
+	l.Insert(len(l.values), values...)
+}
+
+// Remove removes value i and the space that separated it; an index out of range
+// is ignored
+func (l *Line) Remove(i int) {
+	if i < 0 || i >= len(l.values) || l.invalid {
+		return
+	}
+	l.values = slices.Delete(l.values, i, i+1)
+	l.nulls.remove(i)
+	l.quoted.remove(i)
+	if len(l.spaces) > i+1 {
+		l.spaces = slices.Delete(l.spaces, i+1, i+2)
+	} else if len(l.spaces) > i && i > 0 {
+		l.spaces = slices.Delete(l.spaces, i, i+1)
+	}
 }
+
+// Truncate keeps the first n values
+func (l *Line) Truncate(n int) {
+	if n < 0 {
+		n = 0
+	}
+	if n >= len(l.values) || l.invalid {
+		return
+	}
+	l.fitSpaces(len(l.values), n)
+	l.values = l.values[:n]
+	l.nulls.truncate(n)
+	l.quoted.truncate(n)
+}
+
+// grow adds empty values until there are n
+func (l *Line) grow(n int) {
+	if len(l.values) < n {
+		l.fitSpaces(len(l.values), n)
+	}
+	for len(l.values) < n {
+		l.values = append(l.values, "")
+	}
+}
+
+// fitSpaces fits the spaces of m values to n values, keeping the space before
+// the comment last
+func (l *Line) fitSpaces(m, n int) {
+	if len(l.spaces) <= m {
+		if len(l.spaces) > n {
+			l.spaces = l.spaces[:n]
+		}
+		return
+	}
+	trailing := l.spaces[len(l.spaces)-1]
+	l.spaces = l.spaces[:min(len(l.spaces)-1, n)]
+	for len(l.spaces) < n {
+		if len(l.spaces) == 0 {
+			l.spaces = append(l.spaces, "")
+		} else {
+			l.spaces = append(l.spaces, " ")
+		}
+	}
+	l.spaces = append(l.spaces, trailing)
+}
+
 func (l *Line) IsNil(i int) bool {
-	return l.nulls.Bit(i) == 1
+	return l.nulls.has(i)
 }
+
+// SetNil makes value i null, adding empty values to reach index i; a negative
+// index is ignored
 func (l *Line) SetNil(i int) {
-	l.nulls = l.nulls.SetBit(l.nulls, i, 1)
-	for i >= len(l.values) {
-		l.values = append(l.values, "")
+	if i < 0 || l.invalid {
+		return
 	}
+	l.grow(i + 1)
+	l.values[i] = ""
+	l.nulls.set(i)
+	l.quoted.unset(i)
 }
 func (l *Line) UnsetNil(i int) {
-	l.nulls = l.nulls.SetBit(l.nulls, i, 0)
+	if l.invalid {
+		return
+	}
+	l.nulls.unset(i)
 }
 func (l *Line) HasSpaces() bool {
 	return l.spaces != nil && len(l.spaces) > 0
 }
+
+// GetSpaces returns a copy of the spaces
 func (l *Line) GetSpaces() []string {
-	return l.spaces
+	return slices.Clone(l.spaces)
 }
 func (l *Line) SetSpaces(spaces []string) error {
+	if l.invalid {
+		return ErrInvalidLine
+	}
 	if err := ValidateSpaces(spaces); err != nil {
 		return err
 	}
-	l.spaces = spaces
+	l.spaces = slices.Clone(spaces)
 	return nil
 }
+
+// SetSpace sets the space before value i, or before the comment when i is the
+// number of values
 func (l *Line) SetSpace(i int, space string) error {
+	if l.invalid {
+		return ErrInvalidLine
+	}
+	if i < 0 || i > len(l.values) {
+		return fmt.Errorf("Space index %v out of range", i)
+	}
 	if err := ValidateSpace(space, i == 0); err != nil {
 		return err
 	}
+	for len(l.spaces) <= i {
+		if len(l.spaces) == 0 {
+			l.spaces = append(l.spaces, "")
+		} else {
+			l.spaces = append(l.spaces, " ")
+		}
+	}
 	l.spaces[i] = space
 	return nil
 }
@@ -111,6 +271,9 @@ func (l *Line) GetComment() (string, bool) {
 	return l.comment, l.hash
 }
 func (l *Line) SetComment(s string) error {
+	if l.invalid {
+		return ErrInvalidLine
+	}
 	if err := ValidateComment(s); err != nil {
 		return err
 	}
@@ -119,20 +282,39 @@ func (l *Line) SetComment(s string) error {
 	return nil
 }
 func (l *Line) ClearComment() {
+	if l.invalid {
+		return
+	}
 	l.hash = false
 	l.comment = ""
 }
+
+// IsInvalid reports whether the line failed to parse and was kept as text by
+// lenient parsing; such a line has no values and cannot be modified. SetSpace,
+// SetSpaces and SetComment return ErrInvalidLine for it and the mutators that
+// do not return an error do nothing.
+func (l *Line) IsInvalid() bool {
+	return l.invalid
+}
+
+// Text returns the original text of an invalid line
+func (l *Line) Text() string {
+	return l.text
+}
 func (l *Line) String() string {
+	if l.invalid {
+		return l.text
+	}
 	result := make([]string, 0)
 	spacect := len(l.spaces)
 	valuect := len(l.values)
-	for i, v := range l.values {
+	for i := range l.values {
 		if spacect > i {
 			result = append(result, l.spaces[i])
 		} else if i > 0 {
 			result = append(result, " ")
 		}
-		result = append(result, SerializeValue(v, l.IsNil(i)))
+		result = append(result, l.serializeValue(i))
 	}
 	if spacect > valuect {
 		result = append(result, l.spaces[valuect])
@@ -143,14 +325,23 @@ func (l *Line) String() string {
 	}
 	return strings.Join(result, "")
 }
+
+// serializeValue writes value i quoted when it was parsed quoted, otherwise in
+// its shortest form
+func (l *Line) serializeValue(i int) string {
+	v := l.values[i]
+	if !l.quoted.has(i) || l.IsNil(i) || IsSpecial(v) {
+		return SerializeValue(v, l.IsNil(i))
+	}
+	return "\"" + v + "\""
+}
 func (l *Line) ValuesString() string {
 	result := make([]string, 0)
 	for i, v := range l.values {
 		if i != 0 {
 			result = append(result, " ")
 		}
-		null := l.nulls.Bit(i) > 0
-		result = append(result, SerializeValue(v, null))
+		result = append(result, SerializeValue(v, l.IsNil(i)))
 	}
 	return strings.Join(result, "")
 }
@@ -1,6 +1,8 @@
 package wsv
 
 import (
+	"fmt"
+	"slices"
 	"testing"
 )
 
@@ -212,3 +214,124 @@ func TestRoundTrip(t *testing.T) {
 	}
 
 }
+
+func TestLineGetValues(t *testing.T) {
+	l, _ := ParseLine("a  b", true)
+	l.GetValues()[0] = "x"
+	l.GetSpaces()[1] = "#"
+	if l.String() != "a  b" || l.GetValue(1) != "b" || l.GetValue(2) != "" {
+		t.Errorf("expected line to be unchanged, got %q", l.String())
+	}
+}
+
+func TestLineEdit(t *testing.T) {
+	type test struct {
+		input    string
+		edit     func(l *Line)
+		expected string
+	}
+	tests := []test{
+		{"a  b", func(l *Line) { l.Append("c") }, "a  b c"},
+		{"a  b  #c", func(l *Line) { l.Append("c", "-") }, "a  b c \"-\"  #c"},
+		{"a  #c", func(l *Line) { l.Append("b") }, "a b  #c"},
+		{"a\t#c", func(l *Line) { l.Append("b"); l.SetComment("d") }, "a b\t#d"},
+		{"  #c", func(l *Line) { l.Append("a", "b") }, "a b  #c"},
+		{"a  b  #c", func(l *Line) { l.Insert(1, "x") }, "a  x b  #c"},
+		{"  a  b", func(l *Line) { l.Insert(0, "x") }, "  x a  b"},
+		{"a - b", func(l *Line) { l.Insert(1, "x") }, "a x - b"},
+		{"a - b", func(l *Line) { l.Insert(4, "x") }, "a - b"},
+		{"  a  -  b", func(l *Line) { l.Remove(0) }, "  -  b"},
+		{"a  -  b", func(l *Line) { l.Remove(1) }, "a  b"},
+		{"a  b  c", func(l *Line) { l.Remove(2) }, "a  b"},
+		{"a  b  c #x", func(l *Line) { l.Remove(2) }, "a  b  #x"},
+		{"a - c", func(l *Line) { l.Remove(-1) }, "a - c"},
+		{"a - c  #x", func(l *Line) { l.Truncate(2) }, "a -  #x"},
+		{"a - c", func(l *Line) { l.Truncate(-1) }, ""},
+		{"a  b  c  d", func(l *Line) { l.SetValues([]string{"x", "y"}) }, "x  y"},
+		{"a #x", func(l *Line) { l.SetValues([]string{"x", "y"}) }, "x y #x"},
+		{"a", func(l *Line) { l.SetValue(2, "c") }, "a \"\" c"},
+		{"-", func(l *Line) { l.SetValue(0, "a") }, "a"},
+		{"a #x", func(l *Line) { l.SetNil(1) }, "a - #x"},
+		{"a", func(l *Line) { l.SetNil(2) }, "a \"\" -"},
+		{"a", func(l *Line) { l.SetValue(-1, "x"); l.SetNil(-1) }, "a"},
+		{"\"a\" \"b\"", func(l *Line) { l.Insert(1, "x") }, "\"a\" x \"b\""},
+		{"\"a\"  \"b\"  c", func(l *Line) { l.Remove(0) }, "\"b\"  c"},
+		{"\"a\" \"b\"", func(l *Line) { l.SetValue(0, "x") }, "x \"b\""},
+		{"\"a\" \"b\"", func(l *Line) { l.SetNil(1) }, "\"a\" -"},
+		{"\"a\" \"b\" \"c\"", func(l *Line) { l.Truncate(1); l.Append("d") }, "\"a\" d"},
+	}
+	for i, test := range tests {
+		l, err := ParseLine(test.input, true)
+		if err != nil {
+			t.Fatalf("%v: unexpected error %v", i, err)
+		}
+		test.edit(l)
+		if s := l.String(); s != test.expected {
+			t.Errorf("%v: expected %q, got %q", i, test.expected, s)
+		}
+		if len(l.spaces) > len(l.values)+1 {
+			t.Errorf("%v: expected at most %v spaces, got %q", i, len(l.values)+1, l.spaces)
+		}
+	}
+}
+
+func TestLineNulls(t *testing.T) {
+	l := NewLine()
+	for i := 0; i < 130; i++ {
+		l.Append(fmt.Sprint(i))
+	}
+	l.SetNil(63)
+	l.SetNil(129)
+	l.Insert(0, "x")
+	if !l.IsNil(64) || !l.IsNil(130) || l.IsNil(63) || l.IsNil(129) {
+		t.Errorf("expected nulls to move up with the values")
+	}
+	l.Remove(0)
+	l.Remove(0)
+	if !l.IsNil(62) || !l.IsNil(128) || l.IsNil(63) || l.IsNil(129) {
+		t.Errorf("expected nulls to move down with the values")
+	}
+	l.Truncate(100)
+	l.Append("y")
+	if l.IsNil(128) || l.IsNil(100) || l.Len() != 101 {
+		t.Errorf("expected truncated nulls to be dropped")
+	}
+	if err := l.SetSpace(102, " "); err == nil {
+		t.Errorf("expected error for space out of range")
+	}
+}
+
+func TestLineWideEdit(t *testing.T) {
+	type test struct {
+		len      int
+		edit     func(l *Line)
+		expected []int
+	}
+	tests := []test{
+		{80, func(l *Line) { l.Insert(70, "x") }, []int{63}},
+		{80, func(l *Line) { l.Insert(63, "x") }, []int{64}},
+		{80, func(l *Line) { l.Insert(80, "x") }, []int{63}},
+		{100, func(l *Line) { l.Remove(80) }, []int{63}},
+		{100, func(l *Line) { l.Remove(10) }, []int{62}},
+		{100, func(l *Line) { l.Remove(63) }, []int{}},
+		{100, func(l *Line) { l.Truncate(64) }, []int{63}},
+		{100, func(l *Line) { l.Truncate(63); l.Append("x") }, []int{}},
+	}
+	for i, test := range tests {
+		l := NewLine()
+		for j := 0; j < test.len; j++ {
+			l.Append(fmt.Sprint(j))
+		}
+		l.SetNil(63)
+		test.edit(l)
+		nulls := []int{}
+		for j := 0; j < l.Len(); j++ {
+			if l.IsNil(j) {
+				nulls = append(nulls, j)
+			}
+		}
+		if !slices.Equal(nulls, test.expected) {
+			t.Errorf("%v: expected nulls %v, got %v", i, test.expected, nulls)
+		}
+	}
+}
@@ -0,0 +1,365 @@
+package wsv
+
+import (
+	"bytes"
+	"encoding"
+	"fmt"
+	"io"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+var (
+	textMarshalerType   = reflect.TypeOf((*encoding.TextMarshaler)(nil)).Elem()
+	textUnmarshalerType = reflect.TypeOf((*encoding.TextUnmarshaler)(nil)).Elem()
+)
+
+type field struct {
+	name      string
+	index     int
+	omitEmpty bool
+}
+
+// fields returns the exported fields of struct type t in order, named by their
+// `wsv:"name,omitempty"` tag or else by the field name; a tag of "-" skips a field
+func fields(t reflect.Type) []field {
+	result := make([]field, 0, t.NumField())
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		if !f.IsExported() {
+			continue
+		}
+		tag := f.Tag.Get("wsv")
+		if tag == "-" {
+			continue
+		}
+		name, opts, _ := strings.Cut(tag, ",")
+		if name == "" {
+			name = f.Name
+		}
+		result = append(result, field{name: name, index: i, omitEmpty: opts == "omitempty"})
+	}
+	return result
+}
+
+// structType returns the struct type behind t, which may be a struct or a
+// pointer to one
+func structType(t reflect.Type) (reflect.Type, error) {
+	if t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	if t.Kind() != reflect.Struct {
+		return nil, fmt.Errorf("Cannot map %v to a line", t)
+	}
+	return t, nil
+}
+
+// Marshal returns the UTF-8 encoded document with a line for every element of
+// v, which must be a slice of structs or of pointers to structs. Values are in
+// field order; nil pointers and empty omitempty fields are written as nulls.
+func Marshal(v any) ([]byte, error) {
+	var buf bytes.Buffer
+	e := NewEncoder(&buf)
+	if err := e.Encode(v); err != nil {
+		return nil, err
+	}
+	if err := e.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// Unmarshal parses data into v, which must be a pointer to a slice of structs
+// or of pointers to structs. Values are assigned in field order.
+func Unmarshal(data []byte, v any) error {
+	return NewDecoder(bytes.NewReader(data)).Decode(v)
+}
+
+type Encoder struct {
+	// Header writes a line of field names before the first row
+	Header bool
+
+	w      *Writer
+	t      reflect.Type
+	fields []field
+}
+
+func NewEncoder(w io.Writer) *Encoder {
+	return &Encoder{w: NewWriter(w)}
+}
+
+// Encode writes a struct, a pointer to a struct, or a slice of either as lines
+func (e *Encoder) Encode(v any) error {
+	rv := reflect.ValueOf(v)
+	if rv.Kind() == reflect.Slice || rv.Kind() == reflect.Array {
+		for i := 0; i < rv.Len(); i++ {
+			if err := e.encode(rv.Index(i)); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	return e.encode(rv)
+}
+
+func (e *Encoder) encode(rv reflect.Value) error {
+	if rv.Kind() == reflect.Pointer {
+		if rv.IsNil() {
+			return fmt.Errorf("Cannot encode a nil %v", rv.Type())
+		}
+		rv = rv.Elem()
+	}
+	if rv.Kind() != reflect.Struct {
+		return fmt.Errorf("Cannot map %v to a line", rv.Type())
+	}
+	if e.t != rv.Type() {
+		e.t = rv.Type()
+		e.fields = fields(e.t)
+		if e.Header {
+			names := make([]string, len(e.fields))
+			for i, f := range e.fields {
+				names[i] = f.name
+			}
+			if err := e.w.Write(names); err != nil {
+				return err
+			}
+		}
+	}
+
+	line := NewLine()
+	values := make([]string, len(e.fields))
+	nulls := make([]bool, len(e.fields))
+	for i, f := range e.fields {
+		fv := rv.Field(f.index)
+		if f.omitEmpty && fv.IsZero() {
+			nulls[i] = true
+			continue
+		}
+		s, null, err := marshalValue(fv)
+		if err != nil {
+			return fmt.Errorf("Field %v: %w", f.name, err)
+		}
+		values[i], nulls[i] = s, null
+	}
+	line.SetValues(values)
+	for i, null := range nulls {
+		if null {
+			line.SetNil(i)
+		}
+	}
+	return e.w.WriteLine(line)
+}
+
+func (e *Encoder) Flush() error {
+	e.w.Flush()
+	return e.w.Error()
+}
+
+// Close writes the byte order mark if nothing was encoded and flushes
+func (e *Encoder) Close() error {
+	return e.w.Close()
+}
+
+func marshalValue(v reflect.Value) (string, bool, error) {
+	for v.Kind() == reflect.Pointer || v.Kind() == reflect.Interface {
+		if v.IsNil() {
+			return "", true, nil
+		}
+		if v.Type().Implements(textMarshalerType) {
+			break
+		}
+		v = v.Elem()
+	}
+	if v.Type().Implements(textMarshalerType) {
+		b, err := v.Interface().(encoding.TextMarshaler).MarshalText()
+		return string(b), false, err
+	} else if v.CanAddr() && v.Addr().Type().Implements(textMarshalerType) {
+		b, err := v.Addr().Interface().(encoding.TextMarshaler).MarshalText()
+		return string(b), false, err
+	}
+	switch v.Kind() {
+	case reflect.String:
+		return v.String(), false, nil
+	case reflect.Bool:
+		return strconv.FormatBool(v.Bool()), false, nil
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return strconv.FormatInt(v.Int(), 10), false, nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return strconv.FormatUint(v.Uint(), 10), false, nil
+	case reflect.Float32, reflect.Float64:
+		return strconv.FormatFloat(v.Float(), 'g', -1, v.Type().Bits()), false, nil
+	}
+	return "", false, fmt.Errorf("Unsupported type %v", v.Type())
+}
+
+type Decoder struct {
+	// Header reads the first line with values as field names and assigns the
+	// values of later lines by name instead of by field order
+	Header bool
+
+	r       *Reader
+	names   []string
+	t       reflect.Type
+	columns []int
+}
+
+func NewDecoder(r io.Reader) *Decoder {
+	return &Decoder{r: NewReader(r)}
+}
+
+// Decode reads into v, which is either a pointer to a struct, to read the next
+// line, or a pointer to a slice, to read all remaining lines. Lines without
+// values are skipped. A pointer to a struct returns io.EOF at the end; a nil
+// struct pointer that v points to is only allocated when a line is read.
+func (d *Decoder) Decode(v any) error {
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Pointer || rv.IsNil() {
+		return fmt.Errorf("Cannot decode into %T", v)
+	}
+	rv = rv.Elem()
+	if rv.Kind() == reflect.Pointer && rv.IsNil() {
+		target := reflect.New(rv.Type().Elem())
+		if err := d.decode(target.Elem()); err != nil {
+			return err
+		}
+		rv.Set(target)
+		return nil
+	} else if rv.Kind() == reflect.Pointer {
+		return d.decode(rv.Elem())
+	} else if rv.Kind() != reflect.Slice {
+		return d.decode(rv)
+	}
+	for {
+		elem := reflect.New(rv.Type().Elem()).Elem()
+		target := elem
+		if elem.Kind() == reflect.Pointer {
+			elem.Set(reflect.New(elem.Type().Elem()))
+			target = elem.Elem()
+		}
+		if err := d.decode(target); err == io.EOF {
+			return nil
+		} else if err != nil {
+			return err
+		}
+		rv.Set(reflect.Append(rv, elem))
+	}
+}
+
+func (d *Decoder) next() (*Line, error) {
+	for {
+		line, err := d.r.ReadLine()
+		if err != nil {
+			return nil, err
+		}
+		if line.HasValues() {
+			return line, nil
+		}
+	}
+}
+
+func (d *Decoder) decode(rv reflect.Value) error {
+	if rv.Kind() != reflect.Struct {
+		return fmt.Errorf("Cannot map %v to a line", rv.Type())
+	}
+	if d.Header && d.names == nil {
+		line, err := d.next()
+		if err != nil {
+			return err
+		}
+		d.names = line.GetValues()
+	}
+	line, err := d.next()
+	if err != nil {
+		return err
+	}
+	if d.t != rv.Type() {
+		d.t = rv.Type()
+		d.columns = d.mapColumns(fields(d.t))
+	}
+	for i, value := range line.GetValues() {
+		if i >= len(d.columns) || d.columns[i] < 0 {
+			continue
+		}
+		f := rv.Field(d.columns[i])
+		if err := unmarshalValue(f, value, line.IsNil(i)); err != nil {
+			return fmt.Errorf("Line %v, value %v: %w", d.r.LineIndex(), i, err)
+		}
+	}
+	return nil
+}
+
+// mapColumns returns the struct field index for every value index
+func (d *Decoder) mapColumns(fs []field) []int {
+	if !d.Header {
+		columns := make([]int, len(fs))
+		for i, f := range fs {
+			columns[i] = f.index
+		}
+		return columns
+	}
+	columns := make([]int, len(d.names))
+	for i, name := range d.names {
+		columns[i] = -1
+		for _, f := range fs {
+			if f.name == name {
+				columns[i] = f.index
+				break
+			} else if columns[i] < 0 && strings.EqualFold(f.name, name) {
+				columns[i] = f.index
+			}
+		}
+	}
+	return columns
+}
+
+func unmarshalValue(v reflect.Value, s string, null bool) error {
+	if null {
+		v.SetZero()
+		return nil
+	}
+	if v.Kind() == reflect.Pointer {
+		if v.IsNil() {
+			v.Set(reflect.New(v.Type().Elem()))
+		}
+		if !v.Type().Implements(textUnmarshalerType) {
+			return unmarshalValue(v.Elem(), s, false)
+		}
+	}
+	if v.Type().Implements(textUnmarshalerType) {
+		return v.Interface().(encoding.TextUnmarshaler).UnmarshalText([]byte(s))
+	} else if v.CanAddr() && v.Addr().Type().Implements(textUnmarshalerType) {
+		return v.Addr().Interface().(encoding.TextUnmarshaler).UnmarshalText([]byte(s))
+	}
+	switch v.Kind() {
+	case reflect.String:
+		v.SetString(s)
+	case reflect.Bool:
+		b, err := strconv.ParseBool(s)
+		if err != nil {
+			return err
+		}
+		v.SetBool(b)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		n, err := strconv.ParseInt(s, 10, v.Type().Bits())
+		if err != nil {
+			return err
+		}
+		v.SetInt(n)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		n, err := strconv.ParseUint(s, 10, v.Type().Bits())
+		if err != nil {
+			return err
+		}
+		v.SetUint(n)
+	case reflect.Float32, reflect.Float64:
+		n, err := strconv.ParseFloat(s, v.Type().Bits())
+		if err != nil {
+			return err
+		}
+		v.SetFloat(n)
+	default:
+		return fmt.Errorf("Unsupported type %v", v.Type())
+	}
+	return nil
+}
@@ -0,0 +1,135 @@
+package wsv
+
+import (
+	"bytes"
+	"io"
+	"strings"
+	"testing"
+	"time"
+)
+
+type record struct {
+	Name    string
+	Count   int     `wsv:"count"`
+	Ratio   float64 `wsv:"ratio,omitempty"`
+	Ok      bool
+	Note    *string
+	When    time.Time `wsv:"when"`
+	Skipped string    `wsv:"-"`
+	hidden  string
+}
+
+func TestMarshal(t *testing.T) {
+	note := "a note"
+	when := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+	records := []record{
+		{Name: "a b", Count: 1, Ratio: 0.5, Ok: true, Note: &note, When: when, Skipped: "x", hidden: "y"},
+		{Name: "-", Count: -2},
+	}
+	b, err := Marshal(records)
+	if err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	expected := "\uFEFF\"a b\" 1 0.5 true \"a note\" 2024-01-02T03:04:05Z\n\"-\" -2 - false - 0001-01-01T00:00:00Z"
+	if string(b) != expected {
+		t.Errorf("expected %q, got %q", expected, b)
+	}
+
+	var decoded []record
+	if err := Unmarshal(b, &decoded); err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	if len(decoded) != 2 {
+		t.Fatalf("expected 2 records, got %v", len(decoded))
+	}
+	d := decoded[0]
+	if d.Name != "a b" || d.Count != 1 || d.Ratio != 0.5 || !d.Ok || d.Note == nil || *d.Note != note || !d.When.Equal(when) || d.Skipped != "" {
+		t.Errorf("unexpected record %+v", d)
+	}
+	if decoded[1].Name != "-" || decoded[1].Note != nil || decoded[1].Count != -2 {
+		t.Errorf("unexpected record %+v", decoded[1])
+	}
+
+	b, err = Marshal([]record{})
+	if err != nil || string(b) != "\uFEFF" {
+		t.Errorf("expected a byte order mark for no records, got %q, %v", b, err)
+	}
+}
+
+func TestEncoderDecoderHeader(t *testing.T) {
+	var buf bytes.Buffer
+	e := NewEncoder(&buf)
+	e.Header = true
+	e.Encode(&record{Name: "a", Count: 3})
+	e.Encode(record{Name: "b", Count: 4})
+	if err := e.Flush(); err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	if !strings.HasPrefix(buf.String(), "\uFEFFName count ratio Ok Note when\n") {
+		t.Errorf("expected header, got %q", buf.String())
+	}
+
+	type partial struct {
+		When  string `wsv:"when"`
+		Count *int   `wsv:"COUNT"`
+		Other string
+	}
+	d := NewDecoder(&buf)
+	d.Header = true
+	var p partial
+	if err := d.Decode(&p); err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	if p.Count == nil || *p.Count != 3 || p.When != "0001-01-01T00:00:00Z" {
+		t.Errorf("unexpected record %+v", p)
+	}
+	var rest []*partial
+	if err := d.Decode(&rest); err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	if len(rest) != 1 || *rest[0].Count != 4 {
+		t.Errorf("unexpected records %+v", rest)
+	}
+	if err := d.Decode(&p); err != io.EOF {
+		t.Errorf("expected EOF, got %v", err)
+	}
+}
+
+func TestDecoderPointer(t *testing.T) {
+	d := NewDecoder(strings.NewReader("a 1\nb 2"))
+	var p *record
+	if err := d.Decode(&p); err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	if p == nil || p.Name != "a" || p.Count != 1 {
+		t.Fatalf("unexpected record %+v", p)
+	}
+	first := p
+	if err := d.Decode(&p); err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	if p != first || p.Name != "b" || p.Count != 2 {
+		t.Errorf("expected the record to be reused, got %+v", p)
+	}
+	var q *record
+	if err := d.Decode(&q); err != io.EOF || q != nil {
+		t.Errorf("expected EOF and a nil record, got %v and %+v", err, q)
+	}
+}
+
+func TestUnmarshalErrors(t *testing.T) {
+	var records []record
+	if err := Unmarshal([]byte("a b"), &records); err == nil || !strings.Contains(err.Error(), "value 1") {
+		t.Errorf("expected error for value 1, got %v", err)
+	}
+	if err := Unmarshal([]byte("a"), records); err == nil {
+		t.Errorf("expected error for non-pointer")
+	}
+	var pp **record
+	if err := NewDecoder(strings.NewReader("a")).Decode(&pp); err == nil {
+		t.Errorf("expected error for pointer to pointer")
+	}
+	if _, err := Marshal([]struct{ C chan int }{{}}); err == nil {
+		t.Errorf("expected error for unsupported type")
+	}
+}
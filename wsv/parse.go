@@ -1,7 +1,8 @@
 package wsv
 
 import (
-	"fmt"
+	"context"
+	"errors"
 	"io"
 	"strings"
 
@@ -14,11 +15,25 @@ const (
 	defaultState state = 0 // receiving anything
 	commentState       = 1 // receiving comment chars
 	quotedState        = 2 // receiving a quoted string
-	escapeState        = 3 // just saw a quote char, next char will be a quote, a slash, or a dash
-	expectState        = 4 // next char must be a quote to end a quoted slash or dash
+	escapeState        = 3 // just saw a quote char, next char will be a quote, a slash, or the end of the string
+	expectState        = 4 // next char must be a quote to end a quoted line break
 )
 
 func ParseLine(l string, preserveWhitespaceAndComments bool) (*Line, error) {
+	return parseLine(l, preserveWhitespaceAndComments, 0)
+}
+
+func parseLine(l string, preserveWhitespaceAndComments bool, lineIndex int) (*Line, error) {
+	if !preserveWhitespaceAndComments {
+		// values are all that is kept, which the value parser handles faster
+		p := parsers.Get().(*valueParser)
+		defer parsers.Put(p)
+		if err := p.parseString(l, lineIndex); err != nil {
+			return NewLine(), err
+		}
+		return p.line(string(p.buf)), nil
+	}
+
 	value := strings.Builder{}
 	space := strings.Builder{}
 	comment := strings.Builder{}
@@ -29,27 +44,45 @@ func ParseLine(l string, preserveWhitespaceAndComments bool) (*Line, error) {
 	}
 
 	var state = defaultState
+	var unquoted bool // value holds an unquoted value
+	var column, quoteColumn, quoteOffset int
+	fail := func(kind ErrorKind, column, offset int) error {
+		return &ParseError{LineIndex: lineIndex, Column: column, Offset: offset, Kind: kind}
+	}
+	endValue := func() {
+		if unquoted {
+			eov(line, &value, isNull(&value))
+			unquoted = false
+		}
+	}
+	endSpace := func() {
+		if space.Len() > 0 {
+			eos(line, &space)
+		}
+	}
 
-	for _, r := range l {
+	for offset, r := range l {
 		switch state {
 		case defaultState:
 			if r == 0x0022 { // quote
+				if unquoted {
+					return line, fail(InvalidDoubleQuote, column, offset)
+				}
+				endSpace()
+				quoteColumn, quoteOffset = column, offset
 				state = quotedState
 			} else if r == 0x0023 { // hash
+				endValue()
+				endSpace()
 				line.hash = true
 				state = commentState
-			} else if r == 0x002d { // dash
-				eov(line, &value, true)
-			} else if isWs(r) { // ws
-				if value.Len() > 0 {
-					eov(line, &value, false)
-				}
+			} else if isWs(r) {
+				endValue()
 				space.WriteRune(r)
 			} else {
-				if space.Len() > 0 {
-					eos(line, &space, preserveWhitespaceAndComments)
-				}
+				endSpace()
 				value.WriteRune(r)
+				unquoted = true
 			}
 		case commentState:
 			comment.WriteRune(r)
@@ -64,90 +97,145 @@ func ParseLine(l string, preserveWhitespaceAndComments bool) (*Line, error) {
 				// "...""..." is a quote
 				value.WriteRune(r)
 				state = quotedState
-			} else if r == 0x0023 { // hash
-				// "..."#"..." is a hash
-				value.WriteRune(r)
-				state = expectState
-			} else if r == 0x002d { // dash
-				// "..."-"..." is a dash
-				value.WriteRune(r)
-				state = expectState
 			} else if r == 0x002f { // slash
 				// "..."/"..." is a LF
 				value.WriteRune(0x000a)
 				state = expectState
 			} else {
-				// last quote wasn't an excape, it was the end of the quoted string
-				value.WriteRune(0x0022)
-				value.WriteRune(r)
-				eov(line, &value, false)
-				state = defaultState
+				// last quote wasn't an escape, it was the end of the quoted string
+				eoq(line, &value)
+				if isWs(r) {
+					space.WriteRune(r)
+					state = defaultState
+				} else if r == 0x0023 { // hash
+					line.hash = true
+					state = commentState
+				} else {
+					return line, fail(InvalidCharacterAfterString, column, offset)
+				}
 			}
 		case expectState:
 			if r == 0x0022 {
 				state = quotedState
 			} else {
-				return line, fmt.Errorf("Invalid character after escaped character")
+				return line, fail(InvalidCharacterAfterEscape, column, offset)
 			}
 		}
+		column++
 	}
 
-	if state == quotedState {
-		return line, fmt.Errorf("Quoted string not closed")
-	} else {
-		if value.Len() > 0 {
-			eov(line, &value, false)
-		} else if space.Len() > 0 {
-			eos(line, &space, preserveWhitespaceAndComments)
-		}
-	}
-	if preserveWhitespaceAndComments {
-		line.comment = comment.String()
+	switch state {
+	case quotedState, expectState:
+		return line, fail(UnclosedString, quoteColumn, quoteOffset)
+	case escapeState:
+		eoq(line, &value)
+	default:
+		endValue()
+		endSpace()
 	}
+	line.comment = comment.String()
 	return line, nil
 }
 
 func eov(line *Line, value *strings.Builder, null bool) {
-	// fmt.Printf("eov %v, %v, %v", line, value, null)
-
 	if len(line.values) == 0 && len(line.spaces) == 0 {
 		// ensure that there is a space before the first value
 		line.spaces = append(line.spaces, "")
 	}
 	i := len(line.values)
-	line.values = append(line.values, value.String())
 	if null {
-		line.nulls = line.nulls.SetBit(line.nulls, i, 1)
+		line.values = append(line.values, "")
+		line.nulls.set(i)
+	} else {
+		line.values = append(line.values, value.String())
 	}
 	value.Reset()
 }
-func eos(line *Line, space *strings.Builder, preserveWhitespaceAndComments bool) {
-	// fmt.Printf("eos %v, %v, %v", line, space, preserveWhitespaceAndComments)
 
-	if preserveWhitespaceAndComments {
-		line.spaces = append(line.spaces, space.String())
-	} else {
-		line.spaces = append(line.spaces, " ")
-	}
+// eoq ends a quoted value
+func eoq(line *Line, value *strings.Builder) {
+	eov(line, value, false)
+	line.quoted.set(len(line.values) - 1)
+}
+
+// isNull reports whether an unquoted value is the null value -
+func isNull(value *strings.Builder) bool {
+	return value.Len() == 1 && value.String() == "-"
+}
+
+func eos(line *Line, space *strings.Builder) {
+	line.spaces = append(line.spaces, space.String())
 	space.Reset()
 }
 
 func Parse(r io.Reader, preserveWhitespaceAndComments bool, lineIndexOffset int) ([]Line, error) {
+	return ParseContext(context.Background(), r, preserveWhitespaceAndComments, lineIndexOffset, nil)
+}
+
+func ParseContext(ctx context.Context, r io.Reader, preserveWhitespaceAndComments bool, lineIndexOffset int, limits *Limits) ([]Line, error) {
 	lines := make([]Line, 0)
+	err := ParseEach(ctx, r, preserveWhitespaceAndComments, lineIndexOffset, limits, func(lineIndex int, line *Line) error {
+		lines = append(lines, *line)
+		return nil
+	})
+	return lines, err
+}
 
-	s := rtxt.ScanLines(r)
+// contextCheckInterval is the number of lines parsed between checks of the context
+const contextCheckInterval = 1024
 
-	lineIndex := lineIndexOffset - 1
-	for s.Scan() {
-		lineIndex++
-		if lineIndex < lineIndexOffset {
-			continue
+// ParseEach parses r line by line and calls fn for every line, stopping at the
+// first error from parsing, from fn, or from ctx once it is done.
+func ParseEach(ctx context.Context, r io.Reader, preserveWhitespaceAndComments bool, lineIndexOffset int, limits *Limits, fn func(lineIndex int, line *Line) error) error {
+	return parseEach(ctx, rtxt.NewReader(r), preserveWhitespaceAndComments, lineIndexOffset, limits, nil, fn)
+}
+
+// ParseEachReader is ParseEach reading from rd, which then holds the encoding
+// and compression of the parsed document
+func ParseEachReader(ctx context.Context, rd *rtxt.Reader, preserveWhitespaceAndComments bool, lineIndexOffset int, limits *Limits, fn func(lineIndex int, line *Line) error) error {
+	return parseEach(ctx, rd, preserveWhitespaceAndComments, lineIndexOffset, limits, nil, fn)
+}
+
+// parseEach implements ParseEach; when onError is set it is called for lines
+// that fail to parse and returns the line to use in their place, or nil to
+// skip them
+func parseEach(ctx context.Context, rd *rtxt.Reader, preserveWhitespaceAndComments bool, lineIndexOffset int, limits *Limits, onError func(text string, err *ParseError) *Line, fn func(lineIndex int, line *Line) error) error {
+	if limits == nil {
+		limits = &Limits{}
+	}
+	rd.MaxLineLength = limits.MaxLineLength
+	for i := 0; ; i++ {
+		if i%contextCheckInterval == 0 {
+			if err := ctx.Err(); err != nil {
+				return err
+			}
+		}
+		lineIndex := lineIndexOffset + i
+		text, err := rd.ReadLine()
+		if err == io.EOF {
+			return nil
+		} else if errors.Is(err, rtxt.ErrLineTooLong) {
+			return &LimitError{LineIndex: lineIndex, Limit: limits.MaxLineLength, Err: ErrLineTooLong}
+		} else if err != nil {
+			return err
+		}
+		if limits.MaxLines > 0 && i >= limits.MaxLines {
+			return &LimitError{LineIndex: lineIndex, Limit: limits.MaxLines, Err: ErrTooManyLines}
+		}
+		line, err := parseLine(text, preserveWhitespaceAndComments, lineIndex)
+		var pe *ParseError
+		if err != nil && onError != nil && errors.As(err, &pe) {
+			if line = onError(text, pe); line == nil {
+				continue
+			}
+		} else if err != nil {
+			return err
+		}
+		if limits.MaxValues > 0 && line.Len() > limits.MaxValues {
+			return &LimitError{LineIndex: lineIndex, Limit: limits.MaxValues, Err: ErrTooManyValues}
 		}
-		if line, err := ParseLine(s.Text(), preserveWhitespaceAndComments); err != nil {
-			return lines, err
-		} else {
-			lines = append(lines, *line)
+		if err := fn(lineIndex, line); err != nil {
+			return err
 		}
 	}
-	return lines, nil
 }
@@ -1,7 +1,9 @@
 package wsv
 
 import (
+	"errors"
 	"fmt"
+	"strings"
 	"testing"
 )
 
@@ -12,76 +14,79 @@ func TestParse(t *testing.T) {
 		minimal  string
 	}
 	tests := []test{
-		// {"", "", ""},
-		// {" ", " ", ""},
-		// {"  ", "  ", ""},
-		// {"a", "a", "a"},
-		// {"a ", "a ", "a"},
-		// {"a  ", "a  ", "a"},
-		// {" a", " a", "a"},
-		// {"  a", "  a", "a"},
-		// {"  a  ", "  a  ", "a"},
-		// {"a b", "a b", "a b"},
-		// {"a  b", "a  b", "a b"},
-		// {" a b", " a b", "a b"},
-		// {"  a b", "  a b", "a b"},
-		// {"  a  b", "  a  b", "a b"},
-		// {"a b ", "a b ", "a b"},
-		// {"a  b  ", "a  b  ", "a b"},
-		// {" a b ", " a b ", "a b"},
-		// {"  a b ", "  a b ", "a b"},
-		// {"  a  b  ", "  a  b  ", "a b"},
+		{"", "", ""},
+		{" ", " ", ""},
+		{"  ", "  ", ""},
+		{"a", "a", "a"},
+		{"a ", "a ", "a"},
+		{"a  ", "a  ", "a"},
+		{" a", " a", "a"},
+		{"  a", "  a", "a"},
+		{"  a  ", "  a  ", "a"},
+		{"a b", "a b", "a b"},
+		{"a  b", "a  b", "a b"},
+		{" a b", " a b", "a b"},
+		{"  a b", "  a b", "a b"},
+		{"  a  b", "  a  b", "a b"},
+		{"a b ", "a b ", "a b"},
+		{"a  b  ", "a  b  ", "a b"},
+		{" a b ", " a b ", "a b"},
+		{"  a b ", "  a b ", "a b"},
+		{"  a  b  ", "  a  b  ", "a b"},
 		{"#", "#", ""},
-		// {" #", " #", ""},
-		// {"  #", "  #", ""},
-		// {"a#", "a#", "a"},
-		// {"a #", "a #", "a"},
-		// {"a  #", "a  #", "a"},
-		// {" a#", " a#", "a"},
-		// {"  a#", "  a#", "a"},
-		// {"  a  #", "  a  #", "a"},
-		// {"a b#", "a b#", "a b"},
-		// {"a  b#", "a  b#", "a b"},
-		// {" a b#", " a b#", "a b"},
-		// {"  a b#", "  a b#", "a b"},
-		// {"  a  b#", "  a  b#", "a b"},
-		// {"a b #", "a b #", "a b"},
-		// {"a  b  #", "a  b  #", "a b"},
-		// {" a b #", " a b #", "a b"},
-		// {"  a b #", "  a b #", "a b"},
-		// {"  a  b  #", "  a  b  #", "a b"},
-		// {"#c", "#c", ""},
-		// {" #c", " #c", ""},
-		// {"  #c", "  #c", ""},
-		// {"a#c", "a#c", "a"},
-		// {"a #c", "a #c", "a"},
-		// {"a  #c", "a  #c", "a"},
-		// {" a#c", " a#c", "a"},
-		// {"  a#c", "  a#c", "a"},
-		// {"  a  #c", "  a  #c", "a"},
-		// {"a b#c", "a b#c", "a b"},
-		// {"a  b#c", "a  b#c", "a b"},
-		// {" a b#c", " a b#c", "a b"},
-		// {"  a b#c", "  a b#c", "a b"},
-		// {"  a  b#c", "  a  b#c", "a b"},
-		// {"a b #c", "a b #c", "a b"},
-		// {"a  b  #c", "a  b  #c", "a b"},
-		// {" a b #c", " a b #c", "a b"},
-		// {"  a b #c", "  a b #c", "a b"},
-		// {"  a  b  #c", "  a  b  #c", "a b"},
-		// {"\uD834\uDD1E", "\uD834\uDD1E", "\uD834\uDD1E"},
-		// {"#\uD834\uDD1E", "#\uD834\uDD1E", ""},
-		// {`""`, `""`, `""`},
-		// {`"" `, `"" `, `""`},
-		// {`"\uD834\uDD1E"`, `\uD834\uDD1E`, `\uD834\uDD1E`},
-		// {"-", "-", "-"},
-		// {"-a", "-a", "-a"},
+		{" #", " #", ""},
+		{"  #", "  #", ""},
+		{"a#", "a#", "a"},
+		{"a #", "a #", "a"},
+		{"a  #", "a  #", "a"},
+		{" a#", " a#", "a"},
+		{"  a#", "  a#", "a"},
+		{"  a  #", "  a  #", "a"},
+		{"a b#", "a b#", "a b"},
+		{"a  b#", "a  b#", "a b"},
+		{" a b#", " a b#", "a b"},
+		{"  a b#", "  a b#", "a b"},
+		{"  a  b#", "  a  b#", "a b"},
+		{"a b #", "a b #", "a b"},
+		{"a  b  #", "a  b  #", "a b"},
+		{" a b #", " a b #", "a b"},
+		{"  a b #", "  a b #", "a b"},
+		{"  a  b  #", "  a  b  #", "a b"},
+		{"#c", "#c", ""},
+		{" #c", " #c", ""},
+		{"  #c", "  #c", ""},
+		{"a#c", "a#c", "a"},
+		{"a #c", "a #c", "a"},
+		{"a  #c", "a  #c", "a"},
+		{" a#c", " a#c", "a"},
+		{"  a#c", "  a#c", "a"},
+		{"  a  #c", "  a  #c", "a"},
+		{"a b#c", "a b#c", "a b"},
+		{"a  b#c", "a  b#c", "a b"},
+		{" a b#c", " a b#c", "a b"},
+		{"  a b#c", "  a b#c", "a b"},
+		{"  a  b#c", "  a  b#c", "a b"},
+		{"a b #c", "a b #c", "a b"},
+		{"a  b  #c", "a  b  #c", "a b"},
+		{" a b #c", " a b #c", "a b"},
+		{"  a b #c", "  a b #c", "a b"},
+		{"  a  b  #c", "  a  b  #c", "a b"},
+		{"𝄞", "𝄞", "𝄞"},
+		{"#𝄞", "#𝄞", ""},
+		{`""`, `""`, `""`},
+		{`"" `, `"" `, `""`},
+		{`"𝄞"`, `"𝄞"`, `𝄞`},
+		{"-", "-", "-"},
+		{"-a", "-a", "-a"},
+		{`"a"`, `"a"`, "a"},
+		{`"a" "b"#c`, `"a" "b"#c`, "a b"},
+		{` "-"  - `, ` "-"  - `, `"-" -`},
+		{`"a""b"/"c" d`, `"a""b"/"c" d`, `"a""b"/"c" d`},
 	}
 
 	for i, test := range tests {
 		if l, err := ParseLine(test.input, true); err == nil {
-			fmt.Println(l.comment)
-
+			// serialized from the parsed values, quoting, spaces and comment
 			preserve := l.String()
 			if preserve != test.preserve {
 				t.Errorf("%v: expected %v, got %v", i, test.preserve, preserve)
@@ -110,3 +115,68 @@ func TestParse(t *testing.T) {
 		}
 	}
 }
+
+func TestParseLongLine(t *testing.T) {
+	long := strings.Repeat("a", 100000)
+	lines, err := Parse(strings.NewReader("x\n"+long+" b\ny"), true, 0)
+	if err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	if len(lines) != 3 {
+		t.Fatalf("expected 3 lines, got %v", len(lines))
+	}
+	if values := lines[1].GetValues(); len(values) != 2 || values[0] != long || values[1] != "b" {
+		t.Errorf("long line was not parsed")
+	}
+}
+
+func TestParseError(t *testing.T) {
+	tests := []struct {
+		input  string
+		kind   ErrorKind
+		column int
+		offset int
+	}{
+		{`a "bc`, UnclosedString, 2, 2},
+		{`äö "b`, UnclosedString, 3, 5},
+		{`"a"/`, UnclosedString, 0, 0},
+		{`"ä"b`, InvalidCharacterAfterString, 3, 4},
+		{`"a"/x"`, InvalidCharacterAfterEscape, 4, 4},
+	}
+	for i, test := range tests {
+		_, err := ParseLine(test.input, false)
+		var pe *ParseError
+		if !errors.As(err, &pe) {
+			t.Errorf("%v: expected ParseError, got %v", i, err)
+		} else if pe.Kind != test.kind || pe.Column != test.column || pe.Offset != test.offset {
+			t.Errorf("%v: expected %v at %v/%v, got %v at %v/%v", i, test.kind, test.column, test.offset, pe.Kind, pe.Column, pe.Offset)
+		}
+	}
+
+	_, err := Parse(strings.NewReader("a\nb\nc \"d"), false, 10)
+	var pe *ParseError
+	if !errors.As(err, &pe) || pe.LineIndex != 12 || pe.Column != 2 {
+		t.Errorf("expected ParseError on line 12, got %v", err)
+	} else if err.Error() != "Line 12, column 2: Quoted string not closed" {
+		t.Errorf("unexpected message %q", err.Error())
+	}
+
+	r := NewReader(strings.NewReader("a\n\"b\"c"))
+	r.Read()
+	if _, err := r.Read(); !errors.As(err, &pe) || pe.LineIndex != 1 || pe.Kind != InvalidCharacterAfterString {
+		t.Errorf("expected ParseError on line 1, got %v", err)
+	}
+}
+
+func BenchmarkParseLine(b *testing.B) {
+	const line = "id name \"full name\" value - 2024-01-02T03:04:05Z\t# comment"
+	for _, preserve := range []bool{false, true} {
+		b.Run(fmt.Sprintf("preserve=%v", preserve), func(b *testing.B) {
+			b.SetBytes(int64(len(line)))
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				ParseLine(line, preserve)
+			}
+		})
+	}
+}
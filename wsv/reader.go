@@ -0,0 +1,130 @@
+package wsv
+
+import (
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/wjanssens/rtxt"
+)
+
+var ErrFieldCount = errors.New("Wrong number of values")
+
+// Reader reads lines from a WSV document one at a time, in the manner of
+// encoding/csv.Reader.
+type Reader struct {
+	// PreserveWhitespaceAndComments keeps whitespace and comments on the lines
+	// returned by ReadLine
+	PreserveWhitespaceAndComments bool
+
+	// FieldsPerRecord is the number of values Read expects per line. If positive
+	// every line must have that many values; if 0 it is set by the first line;
+	// if negative lines may have any number of values.
+	FieldsPerRecord int
+
+	// ReuseRecord lets Read return a slice that is overwritten by the next call
+	// to avoid allocating one per line, as in encoding/csv. The values are
+	// never overwritten; they share chunks of text with other lines, so reading
+	// only allocates when a chunk fills up, which is zero allocations per line
+	// when amortized over a document.
+	ReuseRecord bool
+
+	r         *rtxt.Reader
+	lineIndex int
+	parser    valueParser
+	record    []string
+}
+
+func NewReader(r io.Reader) *Reader {
+	return &Reader{
+		r:         rtxt.NewReader(r),
+		lineIndex: -1,
+	}
+}
+
+// ReadLine returns the next line, including lines without values
+func (r *Reader) ReadLine() (*Line, error) {
+	if !r.PreserveWhitespaceAndComments {
+		b, err := r.r.ReadLineBytes()
+		if err != nil {
+			return nil, err
+		}
+		r.lineIndex = r.r.Line()
+		if err := r.parser.parse(b, r.lineIndex); err != nil {
+			return nil, err
+		}
+		return r.parser.line(r.parser.text()), nil
+	}
+	text, err := r.r.ReadLine()
+	if err != nil {
+		return nil, err
+	}
+	r.lineIndex = r.r.Line()
+	line, err := parseLine(text, true, r.lineIndex)
+	if err != nil {
+		return nil, err
+	}
+	return line, nil
+}
+
+// Read returns the values of the next line that has any, skipping empty and
+// comment-only lines. Nulls are returned as empty strings; use IsNil or
+// ReadLine to tell them apart.
+func (r *Reader) Read() ([]string, error) {
+	for {
+		b, err := r.r.ReadLineBytes()
+		if err != nil {
+			return nil, err
+		}
+		r.lineIndex = r.r.Line()
+		if err := r.parser.parse(b, r.lineIndex); err != nil {
+			return nil, err
+		}
+		if len(r.parser.ends) == 0 {
+			continue
+		}
+		var values []string
+		if r.ReuseRecord {
+			r.record = r.parser.values(r.record, r.parser.text())
+			values = r.record
+		} else {
+			values = r.parser.values(make([]string, 0, len(r.parser.ends)), r.parser.text())
+		}
+		if r.FieldsPerRecord > 0 && len(values) != r.FieldsPerRecord {
+			return values, fmt.Errorf("Line %v: %w", r.lineIndex, ErrFieldCount)
+		} else if r.FieldsPerRecord == 0 {
+			r.FieldsPerRecord = len(values)
+		}
+		return values, nil
+	}
+}
+
+// IsNil reports whether value i of the values most recently returned by Read
+// is null
+func (r *Reader) IsNil(i int) bool {
+	return r.parser.nulls.has(i)
+}
+
+// ReadAll reads the values of all remaining lines
+func (r *Reader) ReadAll() ([][]string, error) {
+	records := make([][]string, 0)
+	for {
+		record, err := r.Read()
+		if err == io.EOF {
+			return records, nil
+		} else if err != nil {
+			return records, err
+		}
+		records = append(records, record)
+	}
+}
+
+// LineIndex returns the 0-based index of the line most recently read
+func (r *Reader) LineIndex() int {
+	return r.lineIndex
+}
+
+// Encoding returns the encoding of the document
+func (r *Reader) Encoding() (rtxt.ReliableTxtEncoding, error) {
+	return r.r.Encoding()
+}
@@ -0,0 +1,190 @@
+package wsv
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"runtime"
+	"strings"
+	"testing"
+)
+
+func TestReader(t *testing.T) {
+	const doc = "a b\n# comment\n\n\"c d\" -\n"
+	r := NewReader(strings.NewReader(doc))
+	records, err := r.ReadAll()
+	if err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	if len(records) != 2 || strings.Join(records[1], "|") != "c d|" {
+		t.Errorf("unexpected records %q", records)
+	}
+	if r.LineIndex() != 4 {
+		t.Errorf("expected line index 4, got %v", r.LineIndex())
+	}
+
+	r = NewReader(strings.NewReader(doc))
+	r.PreserveWhitespaceAndComments = true
+	for i := 0; ; i++ {
+		line, err := r.ReadLine()
+		if err == io.EOF {
+			if i != 5 {
+				t.Errorf("expected 5 lines, got %v", i)
+			}
+			break
+		} else if err != nil {
+			t.Fatalf("unexpected error %v", err)
+		}
+		if i == 3 && !line.IsNil(1) {
+			t.Errorf("expected null value")
+		}
+		if i == 1 && line.String() != "# comment" {
+			t.Errorf("expected comment, got %q", line.String())
+		}
+	}
+}
+
+func TestReaderFieldsPerRecord(t *testing.T) {
+	const doc = "a b\nc\nd e f"
+	type test struct {
+		fields  int
+		records int
+	}
+	tests := []test{
+		{0, 1},
+		{2, 1},
+		{-1, 3},
+	}
+	for i, test := range tests {
+		r := NewReader(strings.NewReader(doc))
+		r.FieldsPerRecord = test.fields
+		records, err := r.ReadAll()
+		if len(records) != test.records {
+			t.Errorf("%v: expected %v records, got %v", i, test.records, len(records))
+		}
+		if test.fields >= 0 && !errors.Is(err, ErrFieldCount) {
+			t.Errorf("%v: expected field count error, got %v", i, err)
+		}
+	}
+}
+
+func TestReaderError(t *testing.T) {
+	r := NewReader(strings.NewReader("a\n\"b"))
+	r.Read()
+	if _, err := r.Read(); err == nil || !strings.HasPrefix(err.Error(), "Line 1") {
+		t.Errorf("expected error on line 1, got %v", err)
+	}
+}
+
+func TestReaderReuseRecord(t *testing.T) {
+	r := NewReader(strings.NewReader("a - \"-\"\nb \"\" c"))
+	r.ReuseRecord = true
+	first, _ := r.Read()
+	if strings.Join(first, "|") != "a||-" || r.IsNil(0) || !r.IsNil(1) || r.IsNil(2) {
+		t.Errorf("unexpected first record %q", first)
+	}
+	a := first[0]
+	second, _ := r.Read()
+	if strings.Join(second, "|") != "b||c" || r.IsNil(1) {
+		t.Errorf("unexpected second record %q", second)
+	}
+	if &first[0] != &second[0] {
+		t.Errorf("expected record to be reused")
+	}
+	if a != "a" {
+		t.Errorf("expected value to survive the next read, got %q", a)
+	}
+}
+
+// benchmarkDoc is a table of mostly ASCII values with some quoting and nulls
+var benchmarkDoc = strings.Repeat("id name \"full name\" value - 2024-01-02T03:04:05Z\t# comment\n", 10000)
+
+func BenchmarkReaderRead(b *testing.B) {
+	for _, reuse := range []bool{false, true} {
+		b.Run(fmt.Sprintf("reuse=%v", reuse), func(b *testing.B) {
+			b.SetBytes(int64(len(benchmarkDoc)))
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				r := NewReader(strings.NewReader(benchmarkDoc))
+				r.ReuseRecord = reuse
+				for {
+					if _, err := r.Read(); err != nil {
+						break
+					}
+				}
+			}
+		})
+	}
+}
+
+// readAllocs returns the allocations per line of Read with ReuseRecord; the
+// value text is copied into shared chunks, so the occasional allocation of a
+// chunk is amortized and AllocsPerRun, which rounds down, reports 0
+func readAllocs() float64 {
+	r := NewReader(strings.NewReader(benchmarkDoc))
+	r.ReuseRecord = true
+	return testing.AllocsPerRun(1000, func() {
+		r.Read()
+	})
+}
+
+func TestReaderReadAllocs(t *testing.T) {
+	if allocs := readAllocs(); allocs != 0 {
+		t.Errorf("expected no allocations per line, got %v", allocs)
+	}
+
+	// across the whole document only the chunks holding the value text are
+	// allocated, which is less than the document itself
+	lines := strings.Count(benchmarkDoc, "\n")
+	r := NewReader(strings.NewReader(benchmarkDoc))
+	r.ReuseRecord = true
+	var before, after runtime.MemStats
+	runtime.GC()
+	runtime.ReadMemStats(&before)
+	for {
+		if _, err := r.Read(); err != nil {
+			break
+		}
+	}
+	runtime.ReadMemStats(&after)
+	if n := after.Mallocs - before.Mallocs; n > uint64(lines/50) {
+		t.Errorf("expected at most %v allocations for %v lines, got %v", lines/50, lines, n)
+	}
+	if n := after.TotalAlloc - before.TotalAlloc; n > uint64(len(benchmarkDoc)) {
+		t.Errorf("expected at most %v bytes allocated, got %v", len(benchmarkDoc), n)
+	}
+}
+
+// BenchmarkReaderReadReuse reads one line per op with ReuseRecord
+func BenchmarkReaderReadReuse(b *testing.B) {
+	if allocs := readAllocs(); allocs != 0 {
+		b.Fatalf("expected no allocations per line, got %v", allocs)
+	}
+	r := NewReader(strings.NewReader(benchmarkDoc))
+	r.ReuseRecord = true
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		if _, err := r.Read(); err == io.EOF {
+			r = NewReader(strings.NewReader(benchmarkDoc))
+			r.ReuseRecord = true
+		}
+	}
+}
+
+func BenchmarkReaderReadLine(b *testing.B) {
+	for _, preserve := range []bool{false, true} {
+		b.Run(fmt.Sprintf("preserve=%v", preserve), func(b *testing.B) {
+			b.SetBytes(int64(len(benchmarkDoc)))
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				r := NewReader(strings.NewReader(benchmarkDoc))
+				r.PreserveWhitespaceAndComments = preserve
+				for {
+					if _, err := r.ReadLine(); err != nil {
+						break
+					}
+				}
+			}
+		})
+	}
+}
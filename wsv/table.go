@@ -0,0 +1,189 @@
+package wsv
+
+import (
+	"fmt"
+	"strconv"
+	"time"
+)
+
+type ColumnType int
+
+const (
+	// NullColumn has no values other than nulls
+	NullColumn   ColumnType = 0
+	BoolColumn   ColumnType = 1
+	IntColumn    ColumnType = 2
+	FloatColumn  ColumnType = 3
+	TimeColumn   ColumnType = 4
+	StringColumn ColumnType = 5
+)
+
+func (t ColumnType) String() string {
+	switch t {
+	case BoolColumn:
+		return "bool"
+	case IntColumn:
+		return "int"
+	case FloatColumn:
+		return "float"
+	case TimeColumn:
+		return "time"
+	case StringColumn:
+		return "string"
+	default:
+		return "null"
+	}
+}
+
+// Table views lines as rows of columns named by a header line
+type Table struct {
+	columns []string
+	index   map[string]int
+	rows    []*Line
+}
+
+// NewTable uses the first line with values as the header and the following
+// lines with values as rows; empty and comment-only lines are skipped. If strict
+// is set every row must have as many values as the header.
+func NewTable(lines []*Line, strict bool) (*Table, error) {
+	t := &Table{
+		index: make(map[string]int),
+		rows:  make([]*Line, 0),
+	}
+	header := true
+	for i, l := range lines {
+		if !l.HasValues() {
+			continue
+		}
+		if header {
+			t.columns = l.GetValues()
+			for c, name := range t.columns {
+				if _, ok := t.index[name]; !ok {
+					t.index[name] = c
+				}
+			}
+			header = false
+			continue
+		}
+		if strict && l.Len() != len(t.columns) {
+			return t, fmt.Errorf("Line %v: %w", i, ErrFieldCount)
+		}
+		t.rows = append(t.rows, l)
+	}
+	return t, nil
+}
+
+func (d *Document) Table(strict bool) (*Table, error) {
+	return NewTable(d.Lines, strict)
+}
+
+func (t *Table) Columns() []string {
+	return t.columns
+}
+
+// Column returns the index of the named column
+func (t *Table) Column(name string) (int, bool) {
+	c, ok := t.index[name]
+	return c, ok
+}
+
+// Len returns the number of rows, not counting the header
+func (t *Table) Len() int {
+	return len(t.rows)
+}
+
+// Row returns row i, or false when i is out of range
+func (t *Table) Row(i int) (*Line, bool) {
+	if i < 0 || i >= len(t.rows) {
+		return nil, false
+	}
+	return t.rows[i], true
+}
+
+// Get returns the value of the named column in row i. A null or missing value
+// is reported as a ValueError.
+func (t *Table) Get(row int, column string) (string, error) {
+	if row < 0 || row >= len(t.rows) {
+		return "", fmt.Errorf("Row %v out of range", row)
+	}
+	c, ok := t.index[column]
+	if !ok {
+		return "", fmt.Errorf("Unknown column %v", column)
+	}
+	return t.rows[row].get(c)
+}
+
+// Types infers the type of every column from its non-null values. Integer
+// columns containing a float become FloatColumn; any other mix is StringColumn.
+func (t *Table) Types() []ColumnType {
+	types := make([]ColumnType, len(t.columns))
+	for _, row := range t.rows {
+		for c := range types {
+			if c >= row.Len() || row.IsNil(c) {
+				continue
+			}
+			types[c] = mergeTypes(types[c], valueType(row.values[c]))
+		}
+	}
+	return types
+}
+
+func valueType(s string) ColumnType {
+	if _, err := strconv.ParseInt(s, 10, 64); err == nil {
+		return IntColumn
+	} else if _, err := strconv.ParseFloat(s, 64); err == nil && isDecimal(s) {
+		return FloatColumn
+	} else if _, err := strconv.ParseBool(s); err == nil {
+		return BoolColumn
+	} else if _, err := time.Parse(time.RFC3339Nano, s); err == nil {
+		return TimeColumn
+	}
+	return StringColumn
+}
+
+// isDecimal reports whether s is a decimal number with an optional fraction and
+// exponent; ParseFloat also accepts hex floats, underscores, Inf and NaN
+func isDecimal(s string) bool {
+	i := 0
+	if i < len(s) && (s[i] == '+' || s[i] == '-') {
+		i++
+	}
+	digits := 0
+	for ; i < len(s) && s[i] >= '0' && s[i] <= '9'; i++ {
+		digits++
+	}
+	if i < len(s) && s[i] == '.' {
+		for i++; i < len(s) && s[i] >= '0' && s[i] <= '9'; i++ {
+			digits++
+		}
+	}
+	if digits == 0 {
+		return false
+	}
+	if i < len(s) && (s[i] == 'e' || s[i] == 'E') {
+		i++
+		if i < len(s) && (s[i] == '+' || s[i] == '-') {
+			i++
+		}
+		start := i
+		for ; i < len(s) && s[i] >= '0' && s[i] <= '9'; i++ {
+		}
+		if i == start {
+			return false
+		}
+	}
+	return i == len(s)
+}
+
+func mergeTypes(a, b ColumnType) ColumnType {
+	switch {
+	case a == b || b == NullColumn:
+		return a
+	case a == NullColumn:
+		return b
+	case (a == IntColumn && b == FloatColumn) || (a == FloatColumn && b == IntColumn):
+		return FloatColumn
+	default:
+		return StringColumn
+	}
+}
@@ -0,0 +1,100 @@
+package wsv
+
+import (
+	"errors"
+	"testing"
+)
+
+const testTableDoc = `# inventory
+Name    Count  Price  InStock  Updated               Note
+apple   3      1.5    true     2024-01-02T03:04:05Z  -
+pear    -      2      false    2024-02-03T00:00:00Z  -
+
+"kiwi"  10     3      1        2024-03-04T00:00:00Z  -`
+
+func TestTable(t *testing.T) {
+	d, err := ParseDocument(testTableDoc, true)
+	if err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	table, err := d.Table(true)
+	if err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	if table.Len() != 3 || len(table.Columns()) != 6 {
+		t.Fatalf("expected 3 rows of 6 columns, got %v %v", table.Len(), table.Columns())
+	}
+	if c, ok := table.Column("Price"); !ok || c != 2 {
+		t.Errorf("expected Price to be column 2, got %v", c)
+	}
+	if v, err := table.Get(2, "Name"); err != nil || v != "kiwi" {
+		t.Errorf("expected kiwi, got %v %v", v, err)
+	}
+	if row, ok := table.Row(0); !ok {
+		t.Errorf("expected row 0")
+	} else if v, err := row.Float64(2); err != nil || v != 1.5 {
+		t.Errorf("expected 1.5, got %v %v", v, err)
+	}
+	if _, ok := table.Row(3); ok {
+		t.Errorf("expected row 3 to be out of range")
+	}
+	if _, ok := table.Row(-1); ok {
+		t.Errorf("expected row -1 to be out of range")
+	}
+	if _, err := table.Get(1, "Count"); !errors.Is(err, ErrNull) {
+		t.Errorf("expected null, got %v", err)
+	}
+	if _, err := table.Get(0, "Missing"); err == nil {
+		t.Errorf("expected unknown column error")
+	}
+	if _, err := table.Get(3, "Name"); err == nil {
+		t.Errorf("expected row out of range error")
+	}
+
+	expected := []ColumnType{StringColumn, IntColumn, FloatColumn, StringColumn, TimeColumn, NullColumn}
+	for i, typ := range table.Types() {
+		if typ != expected[i] {
+			t.Errorf("column %v: expected %v, got %v", i, expected[i], typ)
+		}
+	}
+}
+
+func TestTableStrict(t *testing.T) {
+	d, _ := ParseDocument("a b\n1 2\n3", true)
+	if table, err := d.Table(false); err != nil || table.Len() != 2 {
+		t.Errorf("expected lenient table, got %v", err)
+	}
+	if _, err := d.Table(true); !errors.Is(err, ErrFieldCount) {
+		t.Errorf("expected field count error, got %v", err)
+	}
+}
+
+func TestValueType(t *testing.T) {
+	tests := []struct {
+		value    string
+		expected ColumnType
+	}{
+		{"12", IntColumn},
+		{"-12", IntColumn},
+		{"+1.5", FloatColumn},
+		{"1.", FloatColumn},
+		{".5", FloatColumn},
+		{"1e3", FloatColumn},
+		{"-2.5E-3", FloatColumn},
+		{"1e", StringColumn},
+		{".", StringColumn},
+		{"NaN", StringColumn},
+		{"Inf", StringColumn},
+		{"-infinity", StringColumn},
+		{"0x1p-2", StringColumn},
+		{"1_000.5", StringColumn},
+		{"1e999", StringColumn},
+		{"true", BoolColumn},
+		{"2024-01-02T03:04:05Z", TimeColumn},
+	}
+	for _, test := range tests {
+		if typ := valueType(test.value); typ != test.expected {
+			t.Errorf("%q: expected %v, got %v", test.value, test.expected, typ)
+		}
+	}
+}
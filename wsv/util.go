@@ -1,39 +1,25 @@
 package wsv
 
-import (
-	"fmt"
-	"slices"
-)
+import "fmt"
 
-var ws = []rune{
-	0x0009,
-	0x000B,
-	0x000C,
-	0x000D,
-	0x0020,
-	0x0085,
-	0x00A0,
-	0x1680,
-	0x2000,
-	0x2001,
-	0x2002,
-	0x2003,
-	0x2004,
-	0x2005,
-	0x2006,
-	0x2007,
-	0x2008,
-	0x2009,
-	0x200A,
-	0x2028,
-	0x2029,
-	0x202F,
-	0x205F,
-	0x3000,
+// asciiWs marks the whitespace characters below 0x80
+var asciiWs = [0x80]bool{
+	0x0009: true,
+	0x000B: true,
+	0x000C: true,
+	0x000D: true,
+	0x0020: true,
 }
 
 func isWs(r rune) bool {
-	return slices.Contains(ws, r)
+	if r < 0x80 {
+		return r >= 0 && asciiWs[r]
+	}
+	switch r {
+	case 0x0085, 0x00A0, 0x1680, 0x2028, 0x2029, 0x202F, 0x205F, 0x3000:
+		return true
+	}
+	return r >= 0x2000 && r <= 0x200A
 }
 
 func ValidateSpaces(spaces []string) error {
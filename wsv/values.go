@@ -0,0 +1,143 @@
+package wsv
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+	"strconv"
+	"time"
+)
+
+var (
+	ErrNull    = errors.New("Value is null")
+	ErrNoValue = errors.New("No value at index")
+)
+
+// ValueError reports a value that could not be read as the requested type;
+// errors.Is(err, ErrNull) tells a null value apart from a malformed one
+type ValueError struct {
+	Index int
+	Value string
+	Err   error
+}
+
+func (e *ValueError) Error() string {
+	if e.Err == ErrNull || e.Err == ErrNoValue {
+		return fmt.Sprintf("Value %v: %v", e.Index, e.Err)
+	}
+	return fmt.Sprintf("Value %v %q: %v", e.Index, e.Value, e.Err)
+}
+
+func (e *ValueError) Unwrap() error {
+	return e.Err
+}
+
+// get returns value i, or a ValueError if it is null or missing
+func (l *Line) get(i int) (string, error) {
+	if i < 0 || i >= len(l.values) {
+		return "", &ValueError{Index: i, Err: ErrNoValue}
+	}
+	if l.IsNil(i) {
+		return "", &ValueError{Index: i, Err: ErrNull}
+	}
+	return l.values[i], nil
+}
+
+// parse reads value i with fn, wrapping a failure in a ValueError
+func parse[T any](l *Line, i int, fn func(s string) (T, error)) (T, error) {
+	s, err := l.get(i)
+	if err != nil {
+		var zero T
+		return zero, err
+	}
+	v, err := fn(s)
+	if err != nil {
+		if ne, ok := err.(*strconv.NumError); ok {
+			err = ne.Err
+		}
+		return v, &ValueError{Index: i, Value: s, Err: err}
+	}
+	return v, nil
+}
+
+func (l *Line) Int(i int) (int, error) {
+	return parse(l, i, strconv.Atoi)
+}
+func (l *Line) SetInt(i int, v int) {
+	l.SetValue(i, strconv.Itoa(v))
+}
+func (l *Line) Int64(i int) (int64, error) {
+	return parse(l, i, func(s string) (int64, error) {
+		return strconv.ParseInt(s, 10, 64)
+	})
+}
+func (l *Line) SetInt64(i int, v int64) {
+	l.SetValue(i, strconv.FormatInt(v, 10))
+}
+func (l *Line) Uint(i int) (uint, error) {
+	return parse(l, i, func(s string) (uint, error) {
+		v, err := strconv.ParseUint(s, 10, 0)
+		return uint(v), err
+	})
+}
+func (l *Line) SetUint(i int, v uint) {
+	l.SetValue(i, strconv.FormatUint(uint64(v), 10))
+}
+func (l *Line) Float64(i int) (float64, error) {
+	return parse(l, i, func(s string) (float64, error) {
+		return strconv.ParseFloat(s, 64)
+	})
+}
+func (l *Line) SetFloat64(i int, v float64) {
+	l.SetValue(i, strconv.FormatFloat(v, 'g', -1, 64))
+}
+func (l *Line) Bool(i int) (bool, error) {
+	return parse(l, i, strconv.ParseBool)
+}
+func (l *Line) SetBool(i int, v bool) {
+	l.SetValue(i, strconv.FormatBool(v))
+}
+func (l *Line) Duration(i int) (time.Duration, error) {
+	return parse(l, i, time.ParseDuration)
+}
+func (l *Line) SetDuration(i int, v time.Duration) {
+	l.SetValue(i, v.String())
+}
+
+// Time reads an RFC 3339 time, with or without fractional seconds
+func (l *Line) Time(i int) (time.Time, error) {
+	return parse(l, i, func(s string) (time.Time, error) {
+		return time.Parse(time.RFC3339Nano, s)
+	})
+}
+func (l *Line) SetTime(i int, v time.Time) {
+	l.SetValue(i, v.Format(time.RFC3339Nano))
+}
+func (l *Line) BigInt(i int) (*big.Int, error) {
+	return parse(l, i, func(s string) (*big.Int, error) {
+		if v, ok := new(big.Int).SetString(s, 10); ok {
+			return v, nil
+		}
+		return nil, fmt.Errorf("Invalid integer")
+	})
+}
+func (l *Line) SetBigInt(i int, v *big.Int) {
+	if v == nil {
+		l.SetNil(i)
+		return
+	}
+	l.SetValue(i, v.String())
+}
+func (l *Line) BigFloat(i int) (*big.Float, error) {
+	return parse(l, i, func(s string) (*big.Float, error) {
+		v, _, err := big.ParseFloat(s, 10, 0, big.ToNearestEven)
+		return v, err
+	})
+}
+func (l *Line) SetBigFloat(i int, v *big.Float) {
+	if v == nil {
+		l.SetNil(i)
+		return
+	}
+	l.SetValue(i, v.Text('g', -1))
+}
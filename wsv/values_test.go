@@ -0,0 +1,91 @@
+package wsv
+
+import (
+	"errors"
+	"math/big"
+	"strconv"
+	"testing"
+	"time"
+)
+
+func TestTypedValues(t *testing.T) {
+	l, err := ParseLine("42 -7 3.5 true 1m30s 2024-01-02T03:04:05.5+01:00 123456789012345678901234567890 1.25 - x", false)
+	if err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	if v, err := l.Int(0); err != nil || v != 42 {
+		t.Errorf("Int: got %v %v", v, err)
+	}
+	if v, err := l.Int64(1); err != nil || v != -7 {
+		t.Errorf("Int64: got %v %v", v, err)
+	}
+	if v, err := l.Uint(0); err != nil || v != 42 {
+		t.Errorf("Uint: got %v %v", v, err)
+	}
+	if v, err := l.Float64(2); err != nil || v != 3.5 {
+		t.Errorf("Float64: got %v %v", v, err)
+	}
+	if v, err := l.Bool(3); err != nil || !v {
+		t.Errorf("Bool: got %v %v", v, err)
+	}
+	if v, err := l.Duration(4); err != nil || v != 90*time.Second {
+		t.Errorf("Duration: got %v %v", v, err)
+	}
+	expected := time.Date(2024, 1, 2, 2, 4, 5, 500000000, time.UTC)
+	if v, err := l.Time(5); err != nil || !v.Equal(expected) {
+		t.Errorf("Time: got %v %v", v, err)
+	}
+	if v, err := l.BigInt(6); err != nil || v.String() != "123456789012345678901234567890" {
+		t.Errorf("BigInt: got %v %v", v, err)
+	}
+	if v, err := l.BigFloat(7); err != nil || v.Text('g', -1) != "1.25" {
+		t.Errorf("BigFloat: got %v %v", v, err)
+	}
+
+	var ve *ValueError
+	if _, err := l.Int(8); !errors.Is(err, ErrNull) || !errors.As(err, &ve) || ve.Index != 8 {
+		t.Errorf("expected null error at 8, got %v", err)
+	}
+	if _, err := l.Int(9); !errors.As(err, &ve) || ve.Index != 9 || !errors.Is(err, strconv.ErrSyntax) {
+		t.Errorf("expected syntax error at 9, got %v", err)
+	}
+	if _, err := l.Uint(1); !errors.As(err, &ve) || ve.Index != 1 {
+		t.Errorf("expected error at 1, got %v", err)
+	}
+	if _, err := l.BigInt(9); !errors.As(err, &ve) || ve.Index != 9 {
+		t.Errorf("expected error at 9, got %v", err)
+	}
+	if _, err := l.Bool(10); !errors.Is(err, ErrNoValue) {
+		t.Errorf("expected missing value error, got %v", err)
+	}
+}
+
+func TestTypedSetters(t *testing.T) {
+	l := NewLine()
+	l.SetInt(0, -1)
+	l.SetInt64(1, 1<<40)
+	l.SetUint(2, 7)
+	l.SetFloat64(3, 0.1)
+	l.SetBool(4, false)
+	l.SetDuration(5, 1500*time.Millisecond)
+	l.SetTime(6, time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC))
+	l.SetBigInt(7, big.NewInt(99))
+	l.SetBigFloat(8, big.NewFloat(2.5))
+	l.SetBigInt(9, nil)
+	expected := "-1 1099511627776 7 0.1 false 1.5s 2024-01-02T03:04:05Z 99 2.5 -"
+	if l.ValuesString() != expected {
+		t.Errorf("expected %q, got %q", expected, l.ValuesString())
+	}
+	l.SetInt(9, 3)
+	if v, err := l.Int(9); err != nil || v != 3 {
+		t.Errorf("expected null to be replaced, got %v %v", v, err)
+	}
+
+	l, _ = ParseLine("a #c", true)
+	l.SetInt(2, 5)
+	v, _ := ParseLine("a #c", true)
+	v.SetValue(2, "5")
+	if l.String() != "a \"\" 5 #c" || l.String() != v.String() {
+		t.Errorf("expected %q, got %q", "a \"\" 5 #c", l.String())
+	}
+}
@@ -0,0 +1,79 @@
+package wsv
+
+import (
+	"io"
+	"strings"
+
+	"github.com/wjanssens/rtxt"
+)
+
+// Writer writes lines to a WSV document one at a time, in the manner of
+// encoding/csv.Writer. Output is buffered; call Flush when done.
+type Writer struct {
+	// Encoding is used for the document; it must be set before the first write
+	Encoding rtxt.ReliableTxtEncoding
+
+	w  io.Writer
+	rw *rtxt.Writer
+}
+
+func NewWriter(w io.Writer) *Writer {
+	return &Writer{
+		w: w,
+	}
+}
+
+// Write writes a line with the given values
+func (w *Writer) Write(record []string) error {
+	values := make([]string, len(record))
+	for i, v := range record {
+		values[i] = SerializeValue(v, false)
+	}
+	return w.writeString(strings.Join(values, " "))
+}
+
+func (w *Writer) WriteLine(l *Line) error {
+	return w.writeString(l.String())
+}
+
+// WriteAll writes all records and flushes
+func (w *Writer) WriteAll(records [][]string) error {
+	for _, record := range records {
+		if err := w.Write(record); err != nil {
+			return err
+		}
+	}
+	w.Flush()
+	return w.Error()
+}
+
+func (w *Writer) writeString(s string) error {
+	if w.rw == nil {
+		w.rw = rtxt.NewWriter(w.w, w.Encoding)
+	}
+	_, err := w.rw.WriteLine(s)
+	return err
+}
+
+// Flush writes any buffered data; use Error to check whether it succeeded
+func (w *Writer) Flush() {
+	if w.rw != nil {
+		w.rw.Flush()
+	}
+}
+
+// Close writes the byte order mark if no line was written and flushes; it does
+// not close the underlying writer
+func (w *Writer) Close() error {
+	if w.rw == nil {
+		w.rw = rtxt.NewWriter(w.w, w.Encoding)
+	}
+	return w.rw.Close()
+}
+
+func (w *Writer) Error() error {
+	if w.rw != nil {
+		return w.rw.Error()
+	}
+	return nil
+}
@@ -0,0 +1,49 @@
+package wsv
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/wjanssens/rtxt"
+)
+
+func TestWriter(t *testing.T) {
+	var buf bytes.Buffer
+	w := NewWriter(&buf)
+	w.Write([]string{"a", "b c", "", "-"})
+	l, _ := ParseLine("  x  -  # comment", true)
+	w.WriteLine(l)
+	if err := w.WriteAll([][]string{{"\"q\""}, {"line\nfeed"}}); err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	expected := "\uFEFFa \"b c\" \"\" \"-\"\n  x  -  # comment\n\"\"\"q\"\"\"\n\"line\"/\"feed\""
+	if buf.String() != expected {
+		t.Errorf("expected %q, got %q", expected, buf.String())
+	}
+
+	r := NewReader(&buf)
+	r.FieldsPerRecord = -1
+	records, err := r.ReadAll()
+	if err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	if len(records) != 4 || records[0][1] != "b c" || records[0][3] != "-" || records[3][0] != "line\nfeed" {
+		t.Errorf("unexpected records %q", records)
+	}
+}
+
+func TestWriterEncoding(t *testing.T) {
+	var buf bytes.Buffer
+	w := NewWriter(&buf)
+	w.Encoding = rtxt.Utf16
+	w.Write([]string{"a"})
+	w.Flush()
+	if !bytes.Equal(buf.Bytes(), []byte{0xfe, 0xff, 0x00, 'a'}) {
+		t.Errorf("unexpected output %x", buf.Bytes())
+	}
+	r := NewReader(&buf)
+	if records, _ := r.ReadAll(); strings.Join(records[0], "") != "a" {
+		t.Errorf("unexpected records %q", records)
+	}
+}